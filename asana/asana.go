@@ -8,9 +8,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
-	"reflect"
 	"strings"
 	"time"
 
@@ -34,6 +34,14 @@ var defaultOptFields = map[string][]string{
 var (
 	// ErrUnauthorized can be returned on any call on response status code 401.
 	ErrUnauthorized = errors.New("asana: unauthorized")
+	// ErrSyncTokenExpired is returned by GetEvents when the sync token is too old.
+	// Events were missed and the resource should be read again in full.
+	ErrSyncTokenExpired = errors.New("asana: sync token expired")
+	// ErrSearchTruncated is returned by SearchTasks when more tasks than fit
+	// on a page were created in the same instant, so some were not found.
+	ErrSearchTruncated = errors.New("asana: search results truncated")
+	// ErrJobFailed is returned by WaitForJob when the job failed.
+	ErrJobFailed = errors.New("asana: job failed")
 )
 
 type (
@@ -51,16 +59,21 @@ type (
 		doer      Doer
 		BaseURL   *url.URL
 		UserAgent string
+		// Retry is the policy used to re-send failed requests.
+		// Requests are not retried when it is nil.
+		Retry *RetryPolicy
+
+		limiter *limiter
 	}
 
 	Workspace struct {
-		ID           int64  `json:"id,omitempty"`
+		GID          GID    `json:"gid,omitempty"`
 		Name         string `json:"name,omitempty"`
 		Organization bool   `json:"is_organization,omitempty"`
 	}
 
 	User struct {
-		ID         int64             `json:"id,omitempty"`
+		GID        GID               `json:"gid,omitempty"`
 		Email      string            `json:"email,omitempty"`
 		Name       string            `json:"name,omitempty"`
 		Photo      map[string]string `json:"photo,omitempty"`
@@ -68,15 +81,91 @@ type (
 	}
 
 	Project struct {
-		ID       int64  `json:"id,omitempty"`
-		Name     string `json:"name,omitempty"`
-		Archived bool   `json:"archived,omitempty"`
-		Color    string `json:"color,omitempty"`
-		Notes    string `json:"notes,omitempty"`
+		GID       GID        `json:"gid,omitempty"`
+		Name      string     `json:"name,omitempty"`
+		Archived  bool       `json:"archived,omitempty"`
+		Color     string     `json:"color,omitempty"`
+		Notes     string     `json:"notes,omitempty"`
+		HTMLNotes string     `json:"html_notes,omitempty"`
+		Workspace *Workspace `json:"workspace,omitempty"`
+		Team      *Team      `json:"team,omitempty"`
+		Owner     *User      `json:"owner,omitempty"`
+		StartOn   string     `json:"start_on,omitempty"`
+		DueOn     string     `json:"due_on,omitempty"`
+		Public    bool       `json:"public,omitempty"`
+		// DefaultView is "list", "board", "calendar" or "timeline".
+		DefaultView         string         `json:"default_view,omitempty"`
+		Members             []User         `json:"members,omitempty"`
+		Followers           []User         `json:"followers,omitempty"`
+		CurrentStatus       *ProjectStatus `json:"current_status,omitempty"`
+		CurrentStatusUpdate *StatusUpdate  `json:"current_status_update,omitempty"`
+		CreatedAt           time.Time      `json:"created_at,omitempty"`
+		ModifiedAt          time.Time      `json:"modified_at,omitempty"`
+		PermalinkURL        string         `json:"permalink_url,omitempty"`
+	}
+
+	// ProjectStatus is the legacy status of a project, superseded by
+	// StatusUpdate.
+	ProjectStatus struct {
+		GID      GID    `json:"gid,omitempty"`
+		Title    string `json:"title,omitempty"`
+		Text     string `json:"text,omitempty"`
+		HTMLText string `json:"html_text,omitempty"`
+		// Color is "green", "yellow", "red", "blue" or "complete".
+		Color     string    `json:"color,omitempty"`
+		Author    *User     `json:"author,omitempty"`
+		CreatedAt time.Time `json:"created_at,omitempty"`
+		CreatedBy *User     `json:"created_by,omitempty"`
+	}
+
+	// ProjectUpdate is used to update a project.
+	ProjectUpdate struct {
+		Name        *string `json:"name,omitempty"`
+		Notes       *string `json:"notes,omitempty"`
+		HTMLNotes   *string `json:"html_notes,omitempty"`
+		Color       *string `json:"color,omitempty"`
+		Archived    *bool   `json:"archived,omitempty"`
+		Public      *bool   `json:"public,omitempty"`
+		StartOn     *string `json:"start_on,omitempty"`
+		DueOn       *string `json:"due_on,omitempty"`
+		DefaultView *string `json:"default_view,omitempty"`
+		Owner       *GID    `json:"owner,omitempty"`
+		Team        *GID    `json:"team,omitempty"`
+	}
+
+	// ProjectDuplicate describes the copy made by DuplicateProject.
+	ProjectDuplicate struct {
+		Name string `json:"name"`
+		// Team of the copy. Defaults to the team of the project.
+		Team GID `json:"team,omitempty"`
+		// Include is a comma-separated list of what to copy besides the
+		// tasks, e.g. "members,notes,forms,task_notes,task_assignee,
+		// task_subtasks,task_attachments,task_dates,task_dependencies,
+		// task_followers,task_tags,task_projects".
+		Include       string                `json:"include,omitempty"`
+		ScheduleDates *ProjectScheduleDates `json:"schedule_dates,omitempty"`
+	}
+
+	// ProjectScheduleDates shifts the dates of the tasks of a duplicated
+	// project so that it starts or ends on a given date.
+	ProjectScheduleDates struct {
+		ShouldSkipWeekends bool   `json:"should_skip_weekends"`
+		StartOn            string `json:"start_on,omitempty"`
+		DueOn              string `json:"due_on,omitempty"`
+	}
+
+	// Job is an asynchronous operation, such as the duplication of a project.
+	Job struct {
+		GID             GID    `json:"gid,omitempty"`
+		ResourceSubtype string `json:"resource_subtype,omitempty"`
+		// Status is "not_started", "in_progress", "succeeded" or "failed".
+		Status     string   `json:"status,omitempty"`
+		NewProject *Project `json:"new_project,omitempty"`
+		NewTask    *Task    `json:"new_task,omitempty"`
 	}
 
 	Task struct {
-		ID             int64         `json:"id,omitempty"`
+		GID            GID           `json:"gid,omitempty"`
 		Assignee       *User         `json:"assignee,omitempty"`
 		AssigneeStatus string        `json:"assignee_status,omitempty"`
 		CreatedAt      time.Time     `json:"created_at,omitempty"`
@@ -89,6 +178,7 @@ type (
 		Notes          string        `json:"notes,omitempty"`
 		ParentTask     *Task         `json:"parent,omitempty"`
 		Projects       []Project     `json:"projects,omitempty"`
+		StartOn        string        `json:"start_on,omitempty"`
 		DueOn          string        `json:"due_on,omitempty"`
 		DueAt          string        `json:"due_at,omitempty"`
 		Followers      []User        `json:"followers,omitempty"`
@@ -99,6 +189,10 @@ type (
 		NumLikes       int64         `json:"num_likes,omitempty"`
 		Tags           []Tag         `json:"tags,omitempty"`
 		Memberships    []Membership  `json:"memberships,omitempty"`
+		// Dependencies are the tasks this task is blocked by, Dependents the
+		// tasks it blocks.
+		Dependencies []Task `json:"dependencies,omitempty"`
+		Dependents   []Task `json:"dependents,omitempty"`
 		// "workspace":    map[string]interface {}{"id":13218399566047.000000,"name":"wacul.co.jp"},
 		External External `json:"external,omitempty"`
 	}
@@ -112,24 +206,39 @@ type (
 	}
 
 	// TaskUpdate is used to update a task.
+	//
+	// CustomFields values, by custom field GID, are best set with
+	// SetCustomField, which checks their type. Raw values are a string for
+	// text fields, a number for number fields, an enum option GID for enum
+	// fields, a []GID of enum options or users for multi_enum and people
+	// fields, and a CFDateValue for date fields. A nil value clears the field.
 	TaskUpdate struct {
-		Assignee     *string               `json:"assignee,omitempty"`
-		Name         *string               `json:"name,omitempty"`
-		Notes        *string               `json:"notes,omitempty"`
-		Hearted      *bool                 `json:"hearted,omitempty"`
-		Completed    *bool                 `json:"completed,omitempty"`
-		CompletedAt  *time.Time            `json:"completed_at,omitempty"`
-		CustomFields map[int64]interface{} `json:"custom_fields,omitempty"`
+		Assignee     *string             `json:"assignee,omitempty"`
+		Name         *string             `json:"name,omitempty"`
+		Notes        *string             `json:"notes,omitempty"`
+		Hearted      *bool               `json:"hearted,omitempty"`
+		Completed    *bool               `json:"completed,omitempty"`
+		CompletedAt  *time.Time          `json:"completed_at,omitempty"`
+		CustomFields map[GID]interface{} `json:"custom_fields,omitempty"`
 	}
 
 	MembershipUpdate struct {
-		ProjectID    int64  `json:"project,omitempty"`
-		InsertAfter  *int64 `json:"insert_after,omitempty"`
-		InsertBefore *int64 `json:"insert_before,omitempty"`
-		Section      *int64 `json:"section,omitempty"`
+		ProjectID    GID `json:"project,omitempty"`
+		InsertAfter  GID `json:"insert_after,omitempty"`
+		InsertBefore GID `json:"insert_before,omitempty"`
+		Section      GID `json:"section,omitempty"`
+	}
+	// ParentUpdate is used to set the parent of a task, optionally before or
+	// after one of the subtasks of the new parent.
+	ParentUpdate struct {
+		// Parent is the new parent task, nil to make the task a top-level task.
+		Parent       *GID `json:"parent"`
+		InsertBefore GID  `json:"insert_before,omitempty"`
+		InsertAfter  GID  `json:"insert_after,omitempty"`
 	}
+
 	Section struct {
-		ID        int64     `json:"id,omitempty"`
+		GID       GID       `json:"gid,omitempty"`
 		CreatedAt time.Time `json:"created_at,omitempty"`
 		Name      string    `json:"name,omitempty"`
 		Project   Project   `json:"project,omitempty"`
@@ -142,22 +251,71 @@ type (
 	}
 
 	Story struct {
-		ID        int64     `json:"id,omitempty"`
+		GID       GID       `json:"gid,omitempty"`
 		CreatedAt time.Time `json:"created_at,omitempty"`
 		CreatedBy User      `json:"created_by,omitempty"`
 		Hearts    []Heart   `json:"hearts,omitempty"`
 		Text      string    `json:"text,omitempty"`
+		HTMLText  string    `json:"html_text,omitempty"`
 		Type      string    `json:"type,omitempty"` // E.g., "comment", "system".
+		// ResourceSubtype tells what a story is about, e.g. "comment_added",
+		// "assigned" or "enum_custom_field_changed".
+		ResourceSubtype string   `json:"resource_subtype,omitempty"`
+		IsPinned        bool     `json:"is_pinned,omitempty"`
+		IsEdited        bool     `json:"is_edited,omitempty"`
+		Target          Resource `json:"target,omitempty"`
+
+		// The fields below describe the change recorded by system stories.
+		// Only those matching ResourceSubtype are set.
+		CustomField        *CustomField    `json:"custom_field,omitempty"`
+		OldName            string          `json:"old_name,omitempty"`
+		NewName            string          `json:"new_name,omitempty"`
+		OldResourceSubtype string          `json:"old_resource_subtype,omitempty"`
+		NewResourceSubtype string          `json:"new_resource_subtype,omitempty"`
+		OldDates           *StoryDates     `json:"old_dates,omitempty"`
+		NewDates           *StoryDates     `json:"new_dates,omitempty"`
+		OldTextValue       string          `json:"old_text_value,omitempty"`
+		NewTextValue       string          `json:"new_text_value,omitempty"`
+		OldNumberValue     *float64        `json:"old_number_value,omitempty"`
+		NewNumberValue     *float64        `json:"new_number_value,omitempty"`
+		OldEnumValue       *CFEnumOptions  `json:"old_enum_value,omitempty"`
+		NewEnumValue       *CFEnumOptions  `json:"new_enum_value,omitempty"`
+		OldMultiEnumValues []CFEnumOptions `json:"old_multi_enum_values,omitempty"`
+		NewMultiEnumValues []CFEnumOptions `json:"new_multi_enum_values,omitempty"`
+		OldDateValue       *CFDateValue    `json:"old_date_value,omitempty"`
+		NewDateValue       *CFDateValue    `json:"new_date_value,omitempty"`
+		OldPeopleValue     []User          `json:"old_people_value,omitempty"`
+		NewPeopleValue     []User          `json:"new_people_value,omitempty"`
+		OldSection         *Section        `json:"old_section,omitempty"`
+		NewSection         *Section        `json:"new_section,omitempty"`
+		Assignee           *User           `json:"assignee,omitempty"`
+		Follower           *User           `json:"follower,omitempty"`
+		Project            *Project        `json:"project,omitempty"`
+	}
+
+	// StoryDates are the dates of a task recorded by a story.
+	StoryDates struct {
+		StartOn string `json:"start_on,omitempty"`
+		DueAt   string `json:"due_at,omitempty"`
+		DueOn   string `json:"due_on,omitempty"`
+	}
+
+	// StoryUpdate is used to update a comment. Only one of Text and HTMLText
+	// can be set.
+	StoryUpdate struct {
+		Text     *string `json:"text,omitempty"`
+		HTMLText *string `json:"html_text,omitempty"`
+		IsPinned *bool   `json:"is_pinned,omitempty"`
 	}
 
 	// Heart represents a ♥ action by a user.
 	Heart struct {
-		ID   int64 `json:"id,omitempty"`
-		User User  `json:"user,omitempty"`
+		GID  GID  `json:"gid,omitempty"`
+		User User `json:"user,omitempty"`
 	}
 
 	Tag struct {
-		ID    int64  `json:"id,omitempty"`
+		GID   GID    `json:"gid,omitempty"`
 		Name  string `json:"name,omitempty"`
 		Color string `json:"color,omitempty"`
 		Notes string `json:"notes,omitempty"`
@@ -165,13 +323,20 @@ type (
 
 	Filter struct {
 		Archived       bool     `url:"archived,omitempty"`
-		Assignee       int64    `url:"assignee,omitempty"`
-		Project        int64    `url:"project,omitempty"`
-		Workspace      int64    `url:"workspace,omitempty"`
+		Assignee       GID      `url:"assignee,omitempty"`
+		Project        GID      `url:"project,omitempty"`
+		Workspace      GID      `url:"workspace,omitempty"`
 		CompletedSince string   `url:"completed_since,omitempty"`
 		ModifiedSince  string   `url:"modified_since,omitempty"`
+		Resource       GID      `url:"resource,omitempty"`
+		Sync           string   `url:"sync,omitempty"`
 		OptFields      []string `url:"opt_fields,comma,omitempty"`
 		OptExpand      []string `url:"opt_expand,comma,omitempty"`
+		Parent         GID      `url:"parent,omitempty"`
+		Owner          GID      `url:"owner,omitempty"`
+		Team           GID      `url:"team,omitempty"`
+		Portfolio      GID      `url:"portfolio,omitempty"`
+		SupportedGoal  GID      `url:"supported_goal,omitempty"`
 		Offset         string   `url:"offset,omitempty"`
 		Limit          uint32   `url:"limit,omitempty"`
 	}
@@ -184,23 +349,44 @@ type (
 		Data     interface{} `json:"data,omitempty"`
 		NextPage *NextPage   `json:"next_page,omitempty"`
 		Errors   []Error     `json:"errors,omitempty"`
+		// Sync and HasMore are only set by the events endpoint.
+		Sync    string `json:"sync,omitempty"`
+		HasMore bool   `json:"has_more,omitempty"`
 	}
 
 	Error struct {
 		Phrase  string `json:"phrase,omitempty"`
 		Message string `json:"message,omitempty"`
+		Help    string `json:"help,omitempty"`
 	}
 
 	Webhook struct {
-		ID       int64    `json:"id,omitempty"`
-		Resource Resource `json:"resource,omitempty"`
-		Target   string   `json:"target,omitempty"`
-		Active   bool     `json:"active,omitempty"`
+		GID       GID             `json:"gid,omitempty"`
+		Resource  Resource        `json:"resource,omitempty"`
+		Target    string          `json:"target,omitempty"`
+		Active    bool            `json:"active,omitempty"`
+		Filters   []WebhookFilter `json:"filters,omitempty"`
+		CreatedAt time.Time       `json:"created_at,omitempty"`
+		// Delivery health, zero until the first delivery succeeded or failed.
+		LastSuccessAt      time.Time `json:"last_success_at,omitempty"`
+		LastFailureAt      time.Time `json:"last_failure_at,omitempty"`
+		LastFailureContent string    `json:"last_failure_content,omitempty"`
+	}
+
+	// WebhookFilter restricts the events a webhook delivers.
+	// Empty fields match any value.
+	WebhookFilter struct {
+		ResourceType    string   `json:"resource_type,omitempty"`
+		ResourceSubtype string   `json:"resource_subtype,omitempty"`
+		Action          string   `json:"action,omitempty"`
+		Fields          []string `json:"fields,omitempty"`
 	}
 
 	Resource struct {
-		ID   int64  `json:"id,omitempty"`
-		Name string `json:"name,omitempty"`
+		GID             GID    `json:"gid,omitempty"`
+		Name            string `json:"name,omitempty"`
+		ResourceType    string `json:"resource_type,omitempty"`
+		ResourceSubtype string `json:"resource_subtype,omitempty"`
 	}
 
 	NextPage struct {
@@ -209,10 +395,16 @@ type (
 		URI    string `json:"uri,omitempty"`
 	}
 
-	// Errors always has at least 1 element when returned.
+	// Errors holds the errors of a failed response. It is usually wrapped
+	// into a kind matching Code, such as *NotFoundError.
 	Errors struct {
+		// Errors is empty when the response body could not be decoded.
 		Errors []Error
 		Code   int
+		// RequestID is the X-Request-Id header of the response.
+		RequestID string
+		// Body is the raw response body, set when it could not be decoded.
+		Body []byte
 	}
 
 	EventSummary struct {
@@ -222,12 +414,12 @@ type (
 		// For example, if user A subscribes to a task and user B modified it, the event’s user will be user B.
 		// NOTE: Some events are generated by the system, and will have null as the user.
 		// API consumers should make sure to handle this case.
-		UserID int64 `json:"user,omitempty"`
+		UserID GID `json:"user,omitempty"`
 		// Resource the event occurred on.
 		// Read-only.
 		// NOTE: The resource that triggered the event may be different from the one that the events were requested for.
 		// For example, a subscription to a project will contain events for tasks contained within the project.
-		ResourceID int64 `json:"resource,omitempty"`
+		ResourceID GID `json:"resource,omitempty"`
 		// Type of the resource that generated the event.
 		// Read-only.
 		// NOTE: Currently, only tasks, projects and stories generate events.
@@ -237,7 +429,7 @@ type (
 		Action string `json:"action,omitempty"`
 		// Parent that resource was added to or removed from. null for other event types.
 		// Read-only.
-		ParentID *int64 `json:"parent,omitempty"`
+		ParentID GID `json:"parent,omitempty"`
 		// Timestamp when the event occurred.
 		// Read-only.
 		CreatedAt time.Time `json:"created_at,omitempty"`
@@ -269,26 +461,284 @@ type (
 		// Timestamp when the event occurred.
 		// Read-only.
 		CreatedAt time.Time `json:"created_at,omitempty"`
+		// Change describes the field that changed. Only set for "changed" actions.
+		// Read-only.
+		Change *EventChange `json:"change,omitempty"`
 	}
 
+	EventChange struct {
+		// Field that changed, e.g. "name" or "assignee".
+		Field string `json:"field,omitempty"`
+		// Action on the field: "changed", "added" or "removed".
+		Action string `json:"action,omitempty"`
+		// NewValue is set for "changed" actions, AddedValue and RemovedValue
+		// for changes of list fields.
+		NewValue     json.RawMessage `json:"new_value,omitempty"`
+		AddedValue   json.RawMessage `json:"added_value,omitempty"`
+		RemovedValue json.RawMessage `json:"removed_value,omitempty"`
+	}
+
+	// CustomField is a custom field definition or, on a task, its value.
+	// Type is "text", "number", "enum", "multi_enum", "date" or "people";
+	// only the value field matching it is set. See the typed accessors.
 	CustomField struct {
-		ID          int64           `json:"id,omitempty"`
-		Name        string          `json:"name,omitempty"`
-		Description string          `json:"description,omitempty"`
-		Type        string          `json:"type,omitempty"`
-		EnumOptions []CFEnumOptions `json:"enum_options,omitempty"`
-		Precision   int64           `json:"precision,omitempty"`
-		TextValue   string          `json:"text_value,omitempty"`
-		NumberValue int64           `json:"number_value,omitempty"`
-		EnumValue   CFEnumOptions   `json:"enum_value,omitempty"`
+		GID             GID             `json:"gid,omitempty"`
+		Name            string          `json:"name,omitempty"`
+		Description     string          `json:"description,omitempty"`
+		Type            string          `json:"type,omitempty"`
+		ResourceSubtype string          `json:"resource_subtype,omitempty"`
+		EnumOptions     []CFEnumOptions `json:"enum_options,omitempty"`
+		Precision       int64           `json:"precision,omitempty"`
+		TextValue       string          `json:"text_value,omitempty"`
+		NumberValue     *float64        `json:"number_value,omitempty"`
+		EnumValue       *CFEnumOptions  `json:"enum_value,omitempty"`
+		MultiEnumValues []CFEnumOptions `json:"multi_enum_values,omitempty"`
+		DateValue       *CFDateValue    `json:"date_value,omitempty"`
+		PeopleValue     []User          `json:"people_value,omitempty"`
+		// DisplayValue is the value formatted as in the Asana UI.
+		DisplayValue string `json:"display_value,omitempty"`
 	}
 
 	CFEnumOptions struct {
-		ID      int64  `json:"id,omitempty"`
+		GID     GID    `json:"gid,omitempty"`
 		Name    string `json:"name,omitempty"`
 		Color   string `json:"color,omitempty"`
 		Enabled bool   `json:"enabled,omitempty"`
 	}
+
+	// CFDateValue is the value of a date custom field. DateTime is only
+	// set when the value has a time.
+	CFDateValue struct {
+		Date     string `json:"date,omitempty"`
+		DateTime string `json:"date_time,omitempty"`
+	}
+
+	// CustomFieldUpdate is used to update a custom field.
+	CustomFieldUpdate struct {
+		Name        *string `json:"name,omitempty"`
+		Description *string `json:"description,omitempty"`
+		Precision   *int64  `json:"precision,omitempty"`
+	}
+
+	// Portfolio groups projects and other portfolios.
+	Portfolio struct {
+		GID          GID                  `json:"gid,omitempty"`
+		Name         string               `json:"name,omitempty"`
+		Color        string               `json:"color,omitempty"`
+		CreatedAt    time.Time            `json:"created_at,omitempty"`
+		CreatedBy    *User                `json:"created_by,omitempty"`
+		Owner        *User                `json:"owner,omitempty"`
+		Workspace    *Workspace           `json:"workspace,omitempty"`
+		Members      []User               `json:"members,omitempty"`
+		Public       bool                 `json:"public,omitempty"`
+		StartOn      string               `json:"start_on,omitempty"`
+		DueOn        string               `json:"due_on,omitempty"`
+		PermalinkURL string               `json:"permalink_url,omitempty"`
+		CustomFields []CustomFieldSetting `json:"custom_field_settings,omitempty"`
+	}
+
+	// PortfolioUpdate is used to update a portfolio.
+	PortfolioUpdate struct {
+		Name    *string `json:"name,omitempty"`
+		Color   *string `json:"color,omitempty"`
+		Public  *bool   `json:"public,omitempty"`
+		StartOn *string `json:"start_on,omitempty"`
+		DueOn   *string `json:"due_on,omitempty"`
+	}
+
+	// PortfolioItemUpdate is used to add a project or portfolio to a
+	// portfolio, optionally before or after another item.
+	PortfolioItemUpdate struct {
+		Item         GID `json:"item"`
+		InsertBefore GID `json:"insert_before,omitempty"`
+		InsertAfter  GID `json:"insert_after,omitempty"`
+	}
+
+	// PortfolioMembership gives a user access to a portfolio.
+	PortfolioMembership struct {
+		GID       GID      `json:"gid,omitempty"`
+		Portfolio Resource `json:"portfolio,omitempty"`
+		User      User     `json:"user,omitempty"`
+	}
+
+	// Goal is an objective tracked by a metric and supported by subgoals and work.
+	Goal struct {
+		GID       GID    `json:"gid,omitempty"`
+		Name      string `json:"name,omitempty"`
+		Notes     string `json:"notes,omitempty"`
+		HTMLNotes string `json:"html_notes,omitempty"`
+		StartOn   string `json:"start_on,omitempty"`
+		DueOn     string `json:"due_on,omitempty"`
+		// Status is "green", "yellow" or "red" while the goal is in
+		// progress, then "achieved", "partial", "missed" or "dropped".
+		Status              string        `json:"status,omitempty"`
+		IsWorkspaceLevel    bool          `json:"is_workspace_level,omitempty"`
+		Workspace           *Workspace    `json:"workspace,omitempty"`
+		Team                *Resource     `json:"team,omitempty"`
+		TimePeriod          *TimePeriod   `json:"time_period,omitempty"`
+		Owner               *User         `json:"owner,omitempty"`
+		Followers           []User        `json:"followers,omitempty"`
+		Metric              *GoalMetric   `json:"metric,omitempty"`
+		CurrentStatusUpdate *StatusUpdate `json:"current_status_update,omitempty"`
+	}
+
+	// GoalUpdate is used to update a goal.
+	GoalUpdate struct {
+		Name       *string `json:"name,omitempty"`
+		Notes      *string `json:"notes,omitempty"`
+		HTMLNotes  *string `json:"html_notes,omitempty"`
+		StartOn    *string `json:"start_on,omitempty"`
+		DueOn      *string `json:"due_on,omitempty"`
+		Status     *string `json:"status,omitempty"`
+		Owner      *GID    `json:"owner,omitempty"`
+		TimePeriod *GID    `json:"time_period,omitempty"`
+	}
+
+	// GoalMetric measures the progress of a goal.
+	GoalMetric struct {
+		GID GID `json:"gid,omitempty"`
+		// Unit is "none", "currency" or "percentage".
+		Unit                string  `json:"unit,omitempty"`
+		CurrencyCode        string  `json:"currency_code,omitempty"`
+		Precision           int64   `json:"precision,omitempty"`
+		InitialNumberValue  float64 `json:"initial_number_value,omitempty"`
+		TargetNumberValue   float64 `json:"target_number_value,omitempty"`
+		CurrentNumberValue  float64 `json:"current_number_value,omitempty"`
+		CurrentDisplayValue string  `json:"current_display_value,omitempty"`
+		// ProgressSource is "manual" or where progress is computed from,
+		// e.g. "subgoal_progress" or "project_task_completion".
+		ProgressSource string `json:"progress_source,omitempty"`
+	}
+
+	// GoalRelationship links a goal to a resource supporting it: a subgoal,
+	// project, portfolio or task.
+	GoalRelationship struct {
+		GID GID `json:"gid,omitempty"`
+		// ResourceSubtype is "subgoal" or "supporting_work".
+		ResourceSubtype    string   `json:"resource_subtype,omitempty"`
+		SupportedGoal      Resource `json:"supported_goal,omitempty"`
+		SupportingResource Resource `json:"supporting_resource,omitempty"`
+		// ContributionWeight is the share of the progress of the supported
+		// goal computed from this resource, between 0 and 1.
+		ContributionWeight float64 `json:"contribution_weight,omitempty"`
+	}
+
+	// GoalRelationshipUpdate is used to add a supporting resource to a goal,
+	// optionally before or after another one.
+	GoalRelationshipUpdate struct {
+		SupportingResource GID      `json:"supporting_resource"`
+		InsertBefore       GID      `json:"insert_before,omitempty"`
+		InsertAfter        GID      `json:"insert_after,omitempty"`
+		ContributionWeight *float64 `json:"contribution_weight,omitempty"`
+	}
+
+	// TimePeriod is a period goals are set for, such as a fiscal quarter.
+	TimePeriod struct {
+		GID         GID    `json:"gid,omitempty"`
+		DisplayName string `json:"display_name,omitempty"`
+		// Period is e.g. "FY", "H1" or "Q1".
+		Period  string      `json:"period,omitempty"`
+		StartOn string      `json:"start_on,omitempty"`
+		EndOn   string      `json:"end_on,omitempty"`
+		Parent  *TimePeriod `json:"parent,omitempty"`
+	}
+
+	// StatusUpdate reports the status of a project, portfolio or goal.
+	StatusUpdate struct {
+		GID      GID    `json:"gid,omitempty"`
+		Title    string `json:"title,omitempty"`
+		Text     string `json:"text,omitempty"`
+		HTMLText string `json:"html_text,omitempty"`
+		// StatusType is "on_track", "at_risk", "off_track", "on_hold" or
+		// "complete", and for goals "achieved", "partial", "missed" or "dropped".
+		StatusType      string    `json:"status_type,omitempty"`
+		ResourceSubtype string    `json:"resource_subtype,omitempty"`
+		Author          *User     `json:"author,omitempty"`
+		CreatedAt       time.Time `json:"created_at,omitempty"`
+		CreatedBy       *User     `json:"created_by,omitempty"`
+		Parent          Resource  `json:"parent,omitempty"`
+	}
+
+	// Team is a group of users of an organization.
+	Team struct {
+		GID             GID        `json:"gid,omitempty"`
+		Name            string     `json:"name,omitempty"`
+		Description     string     `json:"description,omitempty"`
+		HTMLDescription string     `json:"html_description,omitempty"`
+		Organization    *Workspace `json:"organization,omitempty"`
+		PermalinkURL    string     `json:"permalink_url,omitempty"`
+		// Visibility is "secret", "request_to_join" or "public".
+		Visibility string `json:"visibility,omitempty"`
+	}
+
+	// TeamUpdate is used to update a team.
+	TeamUpdate struct {
+		Name            *string `json:"name,omitempty"`
+		Description     *string `json:"description,omitempty"`
+		HTMLDescription *string `json:"html_description,omitempty"`
+		Visibility      *string `json:"visibility,omitempty"`
+	}
+
+	// TeamMembership makes a user a member of a team.
+	TeamMembership struct {
+		GID             GID      `json:"gid,omitempty"`
+		User            User     `json:"user,omitempty"`
+		Team            Resource `json:"team,omitempty"`
+		IsGuest         bool     `json:"is_guest,omitempty"`
+		IsAdmin         bool     `json:"is_admin,omitempty"`
+		IsLimitedAccess bool     `json:"is_limited_access,omitempty"`
+	}
+
+	// Attachment is a file attached to a task or project, uploaded to Asana
+	// or linked from another service.
+	Attachment struct {
+		GID  GID    `json:"gid,omitempty"`
+		Name string `json:"name,omitempty"`
+		// ResourceSubtype is where the file is hosted, e.g. "asana",
+		// "dropbox", "gdrive" or "external".
+		ResourceSubtype string    `json:"resource_subtype,omitempty"`
+		CreatedAt       time.Time `json:"created_at,omitempty"`
+		// DownloadURL is a short-lived URL of the file content, only set
+		// for files hosted by Asana.
+		DownloadURL  string   `json:"download_url,omitempty"`
+		PermanentURL string   `json:"permanent_url,omitempty"`
+		ViewURL      string   `json:"view_url,omitempty"`
+		Host         string   `json:"host,omitempty"`
+		Size         int64    `json:"size,omitempty"`
+		Parent       Resource `json:"parent,omitempty"`
+	}
+
+	// CustomFieldSetting attaches a custom field to a project or portfolio.
+	CustomFieldSetting struct {
+		GID         GID         `json:"gid,omitempty"`
+		CustomField CustomField `json:"custom_field,omitempty"`
+		Parent      Resource    `json:"parent,omitempty"`
+		// IsImportant shows the field in list views of the parent's items.
+		IsImportant bool `json:"is_important,omitempty"`
+	}
+
+	// CustomFieldSettingUpdate is used to add a custom field to a project or
+	// portfolio, optionally before or after another custom field.
+	CustomFieldSettingUpdate struct {
+		CustomField  GID   `json:"custom_field"`
+		IsImportant  *bool `json:"is_important,omitempty"`
+		InsertBefore GID   `json:"insert_before,omitempty"`
+		InsertAfter  GID   `json:"insert_after,omitempty"`
+	}
+
+	// EnumOptionUpdate is used to update an enum option.
+	EnumOptionUpdate struct {
+		Name    *string `json:"name,omitempty"`
+		Color   *string `json:"color,omitempty"`
+		Enabled *bool   `json:"enabled,omitempty"`
+	}
+
+	// EnumOptionInsert moves an enum option before or after another one.
+	EnumOptionInsert struct {
+		EnumOption       GID `json:"enum_option"`
+		BeforeEnumOption GID `json:"before_enum_option,omitempty"`
+		AfterEnumOption  GID `json:"after_enum_option,omitempty"`
+	}
 )
 
 func (f DoerFunc) Do(req *http.Request) (resp *http.Response, err error) {
@@ -304,100 +754,72 @@ func (e *Errors) Error() string {
 	for _, err := range e.Errors {
 		sErrs = append(sErrs, err.Error())
 	}
+	if len(sErrs) == 0 {
+		sErrs = append(sErrs, http.StatusText(e.Code))
+	}
+	if e.RequestID != "" {
+		return fmt.Sprintf("code: %d, %s (request id: %s)", e.Code, strings.Join(sErrs, ", "), e.RequestID)
+	}
 	return fmt.Sprintf("code: %d, %s", e.Code, strings.Join(sErrs, ", "))
 }
 
 // NewClient created new asana client with doer.
 // If doer is nil then http.DefaultClient used intead.
-func NewClient(doer Doer) *Client {
+func NewClient(doer Doer, opts ...ClientOption) *Client {
 	if doer == nil {
 		doer = http.DefaultClient
 	}
 	baseURL, _ := url.Parse(defaultBaseURL)
-	client := &Client{doer: doer, BaseURL: baseURL, UserAgent: userAgent}
+	client := &Client{doer: doer, BaseURL: baseURL, UserAgent: userAgent, limiter: &limiter{}}
+	for _, opt := range opts {
+		opt(client)
+	}
 	return client
 }
 
-func remake(v interface{}) (interface{}, error) {
-	return remakeValue(reflect.ValueOf(v))
+// IterWorkspaces returns an iterator over workspaces.
+func (c *Client) IterWorkspaces(opt *Filter) *WorkspaceIterator {
+	return newIterator[Workspace](c, "workspaces", opt)
 }
 
-func remakeValue(v reflect.Value) (interface{}, error) {
-	if v.Kind() != reflect.Ptr {
-		return nil, errors.New("not pointer")
-	}
-	return reflect.New(v.Type().Elem()).Interface(), nil
+func (c *Client) ListWorkspaces(ctx context.Context, opt *Filter) ([]Workspace, error) {
+	return listAll(ctx, c.IterWorkspaces(opt))
 }
 
-func ve(a interface{}) reflect.Value {
-	return reflect.ValueOf(a).Elem()
+// IterUsers returns an iterator over users.
+func (c *Client) IterUsers(opt *Filter) *UserIterator {
+	return newIterator[User](c, "users", opt)
 }
 
-func appendSliceValue(a1, a2 interface{}) {
-	na := reflect.AppendSlice(ve(a1), ve(a2))
-	ve(a1).Set(na)
+func (c *Client) ListUsers(ctx context.Context, opt *Filter) ([]User, error) {
+	return listAll(ctx, c.IterUsers(opt))
 }
 
-func (c *Client) pagenate(ctx context.Context, path string, opt *Filter, v interface{}) error {
-	for {
-		page, err := remake(v)
-		if err != nil {
-			return err
-		}
-		next, err := c.request(ctx, "GET", path, nil, nil, opt, page)
-		if err != nil {
-			return err
-		}
-		reflect.ValueOf(v).Elem().Set(reflect.AppendSlice(reflect.ValueOf(v).Elem(), reflect.ValueOf(page).Elem()))
-		if next == nil {
-			break
-		} else {
-			newOpt := *opt
-			opt = &newOpt
-			opt.Offset = next.Offset
-		}
-	}
-	return nil
+// IterProjects returns an iterator over projects.
+func (c *Client) IterProjects(opt *Filter) *ProjectIterator {
+	return newIterator[Project](c, "projects", opt)
 }
 
-func (c *Client) ListWorkspaces(ctx context.Context, opt *Filter) ([]Workspace, error) {
-	rets := []Workspace{}
-	if err := c.pagenate(ctx, "workspaces", opt, &rets); err != nil {
-		return nil, err
-	}
-	return rets, nil
+func (c *Client) ListProjects(ctx context.Context, opt *Filter) ([]Project, error) {
+	return listAll(ctx, c.IterProjects(opt))
 }
 
-func (c *Client) ListUsers(ctx context.Context, opt *Filter) ([]User, error) {
-	rets := []User{}
-	if err := c.pagenate(ctx, "users", opt, &rets); err != nil {
-		return nil, err
-	}
-	return rets, nil
+// IterStories returns an iterator over the stories of a task.
+func (c *Client) IterStories(taskGID GID, opt *Filter) *StoryIterator {
+	return newIterator[Story](c, fmt.Sprintf("tasks/%s/stories", taskGID), opt)
 }
 
-func (c *Client) ListProjects(ctx context.Context, opt *Filter) ([]Project, error) {
-	rets := []Project{}
-	if err := c.pagenate(ctx, "projects", opt, &rets); err != nil {
-		return nil, err
-	}
-	return rets, nil
+func (c *Client) ListTaskStories(ctx context.Context, taskGID GID, opt *Filter) ([]Story, error) {
+	return listAll(ctx, c.IterStories(taskGID, opt))
 }
 
-func (c *Client) ListTaskStories(ctx context.Context, taskID int64, opt *Filter) ([]Story, error) {
-	rets := []Story{}
-	if err := c.pagenate(ctx, fmt.Sprintf("tasks/%d/stories", taskID), opt, &rets); err != nil {
-		return nil, err
-	}
-	return rets, nil
+// IterTags returns an iterator over tags.
+func (c *Client) IterTags(opt *Filter) *TagIterator {
+	return newIterator[Tag](c, "tags", opt)
 }
 
 func (c *Client) ListTags(ctx context.Context, opt *Filter) ([]Tag, error) {
-	rets := []Tag{}
-	if err := c.pagenate(ctx, "tags", opt, &rets); err != nil {
-		return nil, err
-	}
-	return rets, nil
+	return listAll(ctx, c.IterTags(opt))
 }
 
 func (c *Client) GetAuthenticatedUser(ctx context.Context, opt *Filter) (User, error) {
@@ -406,9 +828,9 @@ func (c *Client) GetAuthenticatedUser(ctx context.Context, opt *Filter) (User, e
 	return *user, err
 }
 
-func (c *Client) GetUserByID(ctx context.Context, id int64, opt *Filter) (User, error) {
+func (c *Client) GetUserByID(ctx context.Context, gid GID, opt *Filter) (User, error) {
 	user := new(User)
-	err := c.Request(ctx, fmt.Sprintf("users/%d", id), opt, user)
+	err := c.Request(ctx, fmt.Sprintf("users/%s", gid), opt, user)
 	return *user, err
 }
 
@@ -422,6 +844,16 @@ func (c *Client) Request(ctx context.Context, path string, opt *Filter, v interf
 // Also it's possible to do request with nil data and form.
 // The response is populated into v, and any error is returned.
 func (c *Client) request(ctx context.Context, method string, path string, data interface{}, form url.Values, opt *Filter, v interface{}) (*NextPage, error) {
+	res, err := c.call(ctx, method, path, data, form, opt, v)
+	if err != nil {
+		return nil, err
+	}
+	return res.NextPage, nil
+}
+
+// withDefaultOptFields returns opt, or a copy of it using the default
+// fields of path when it sets none.
+func withDefaultOptFields(path string, opt *Filter) *Filter {
 	if opt == nil {
 		opt = &Filter{}
 	}
@@ -431,54 +863,143 @@ func (c *Client) request(ctx context.Context, method string, path string, data i
 		opt = &newOpt
 		opt.OptFields = defaultOptFields[path]
 	}
-	urlStr, err := addOptions(path, opt)
-	if err != nil {
-		return nil, err
-	}
-	rel, err := url.Parse(urlStr)
+	return opt
+}
+
+// call is like request but returns the whole response envelope.
+// The envelope is also returned along with the error of a failed response
+// whose body could be decoded.
+func (c *Client) call(ctx context.Context, method string, path string, data interface{}, form url.Values, opt *Filter, v interface{}) (*Response, error) {
+	u, err := c.resolve(path, withDefaultOptFields(path, opt))
 	if err != nil {
 		return nil, err
 	}
-	u := c.BaseURL.ResolveReference(rel)
-	var body io.Reader
+	var body []byte
+	var contentType string
 	if data != nil {
-		b, err := json.Marshal(request{Data: data})
+		body, err = json.Marshal(request{Data: data})
 		if err != nil {
 			return nil, err
 		}
-		body = bytes.NewReader(b)
+		contentType = "application/json"
 	} else if form != nil {
-		body = strings.NewReader(form.Encode())
+		body = []byte(form.Encode())
+		contentType = "application/x-www-form-urlencoded"
 	}
 
-	req, err := http.NewRequest(method, u.String(), body)
+	resp, err := c.do(ctx, method, u, body, contentType)
 	if err != nil {
 		return nil, err
 	}
+	return decodeResponse(resp, v)
+}
 
-	if data != nil {
-		req.Header.Set("Content-Type", "application/json")
-	} else if form != nil {
-		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+// resolve returns the URL of path with the options of opt.
+func (c *Client) resolve(path string, opt *Filter) (string, error) {
+	urlStr, err := addOptions(path, opt)
+	if err != nil {
+		return "", err
 	}
-
-	req.Header.Set("User-Agent", c.UserAgent)
-	resp, err := c.doer.Do(req.WithContext(ctx))
+	rel, err := url.Parse(urlStr)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
+	return c.BaseURL.ResolveReference(rel).String(), nil
+}
+
+// decodeResponse decodes the body of resp into v and closes it.
+// The Response is also returned with errors whose body could be decoded.
+func decodeResponse(resp *http.Response, v interface{}) (*Response, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusUnauthorized {
 		return nil, ErrUnauthorized
 	}
 
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
 	res := &Response{Data: v}
-	err = json.NewDecoder(resp.Body).Decode(res)
-	if len(res.Errors) > 0 {
-		return nil, &Errors{Errors: res.Errors, Code: resp.StatusCode}
+	if len(bytes.TrimSpace(b)) > 0 {
+		if err := json.Unmarshal(b, res); err != nil {
+			if resp.StatusCode >= http.StatusBadRequest {
+				return nil, newError(resp, nil, b)
+			}
+			return nil, &DecodeError{Code: resp.StatusCode, RequestID: resp.Header.Get("X-Request-Id"), Body: b, Err: err}
+		}
+	}
+	if len(res.Errors) > 0 || resp.StatusCode >= http.StatusBadRequest {
+		return res, newError(resp, res.Errors, nil)
+	}
+	return res, nil
+}
+
+// do sends a request with body, retrying it according to c.Retry.
+// The body is kept in memory so every attempt sends it in full.
+// The returned error is either a transport error or a context error;
+// a response is returned as-is once retries are exhausted.
+func (c *Client) do(ctx context.Context, method, urlStr string, body []byte, contentType string) (*http.Response, error) {
+	retry := c.Retry.canRetry(method)
+	for attempt := 0; ; attempt++ {
+		var r io.Reader
+		if body != nil {
+			r = bytes.NewReader(body)
+		}
+		req, err := http.NewRequest(method, urlStr, r)
+		if err != nil {
+			return nil, err
+		}
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		req.Header.Set("User-Agent", c.UserAgent)
+
+		resp, err := c.send(ctx, req)
+		if err == nil && !retryable(resp.StatusCode) {
+			return resp, nil
+		}
+		if !retry || attempt+1 >= c.Retry.MaxAttempts || ctx.Err() != nil {
+			return resp, err
+		}
+		wait, ok := c.Retry.delay(attempt, resp)
+		if !ok {
+			// The server asked to wait longer than the policy allows.
+			return resp, err
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			// Not enough time left for another attempt.
+			return resp, err
+		}
+		if resp != nil {
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return nil, err
+		}
 	}
-	return res.NextPage, err
+}
+
+// send performs a single attempt of req once the client-side limits allow it.
+// The in-flight slot taken for req is released when the response body is closed.
+func (c *Client) send(ctx context.Context, req *http.Request) (*http.Response, error) {
+	if err := c.limiter.wait(ctx); err != nil {
+		return nil, err
+	}
+	if err := c.limiter.acquire(ctx); err != nil {
+		return nil, err
+	}
+	resp, err := c.doer.Do(req.WithContext(ctx))
+	if err != nil {
+		c.limiter.release()
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		c.limiter.throttled()
+	}
+	resp.Body = &releaseBody{ReadCloser: resp.Body, release: c.limiter.release}
+	return resp, nil
 }
 
 func addOptions(s string, opt interface{}) (string, error) {
@@ -490,7 +1011,12 @@ func addOptions(s string, opt interface{}) (string, error) {
 	if err != nil {
 		return s, err
 	}
-	u.RawQuery = qs.Encode()
+	// Options override parameters already in s.
+	q := u.Query()
+	for k, v := range qs {
+		q[k] = v
+	}
+	u.RawQuery = q.Encode()
 	return u.String(), nil
 }
 
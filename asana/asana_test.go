@@ -2,13 +2,23 @@ package asana
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"path/filepath"
 	"reflect"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 var (
@@ -47,14 +57,34 @@ func TestNewError(t *testing.T) {
 	}
 }
 
+func TestGID(t *testing.T) {
+	var v struct {
+		A GID `json:"a"`
+		B GID `json:"b"`
+		C GID `json:"c"`
+	}
+	if err := json.Unmarshal([]byte(`{"a":"1201234567890123","b":12345,"c":null}`), &v); err != nil {
+		t.Fatalf("Unmarshal returned error: %v", err)
+	}
+	if v.A != "1201234567890123" || v.B != "12345" || v.C != "" {
+		t.Errorf("Unmarshal returned %+v", v)
+	}
+	if g := IntGID(12345); g != v.B {
+		t.Errorf("IntGID(12345) = %q, want %q", g, v.B)
+	}
+	if id, err := v.B.Int64(); err != nil || id != 12345 {
+		t.Errorf("Int64() = %v, %v, want 12345", id, err)
+	}
+}
+
 func TestListWorkspaces(t *testing.T) {
 	setup()
 	defer teardown()
 
 	mux.HandleFunc("/workspaces", func(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprint(w, `{"data":[
-			{"id":1,"name":"Organization 1"},
-			{"id":2,"name":"Organization 2"}
+			{"gid":"1","name":"Organization 1"},
+			{"gid":"2","name":"Organization 2"}
 		]}`)
 	})
 
@@ -64,8 +94,8 @@ func TestListWorkspaces(t *testing.T) {
 	}
 
 	want := []Workspace{
-		{ID: 1, Name: "Organization 1"},
-		{ID: 2, Name: "Organization 2"},
+		{GID: "1", Name: "Organization 1"},
+		{GID: "2", Name: "Organization 2"},
 	}
 
 	if !reflect.DeepEqual(workspaces, want) {
@@ -79,8 +109,8 @@ func TestListUsers(t *testing.T) {
 
 	mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprint(w, `{"data":[
-			{"id":1,"email":"test1@asana.com"},
-			{"id":2,"email":"test2@asana.com"}
+			{"gid":"1","email":"test1@asana.com"},
+			{"gid":"2","email":"test2@asana.com"}
 		]}`)
 	})
 
@@ -90,8 +120,8 @@ func TestListUsers(t *testing.T) {
 	}
 
 	want := []User{
-		{ID: 1, Email: "test1@asana.com"},
-		{ID: 2, Email: "test2@asana.com"},
+		{GID: "1", Email: "test1@asana.com"},
+		{GID: "2", Email: "test2@asana.com"},
 	}
 
 	if !reflect.DeepEqual(users, want) {
@@ -105,8 +135,8 @@ func TestListProjects(t *testing.T) {
 
 	mux.HandleFunc("/projects", func(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprint(w, `{"data":[
-			{"id":1,"name":"Project 1"},
-			{"id":2,"name":"Project 2"}
+			{"gid":"1","name":"Project 1"},
+			{"gid":"2","name":"Project 2"}
 		]}`)
 	})
 
@@ -116,8 +146,8 @@ func TestListProjects(t *testing.T) {
 	}
 
 	want := []Project{
-		{ID: 1, Name: "Project 1"},
-		{ID: 2, Name: "Project 2"},
+		{GID: "1", Name: "Project 1"},
+		{GID: "2", Name: "Project 2"},
 	}
 
 	if !reflect.DeepEqual(projects, want) {
@@ -131,8 +161,8 @@ func TestListTasks(t *testing.T) {
 
 	mux.HandleFunc("/tasks", func(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprint(w, `{"data":[
-			{"id":1,"name":"Task 1"},
-			{"id":2,"name":"Task 2"}
+			{"gid":"1","name":"Task 1"},
+			{"gid":"2","name":"Task 2"}
 		]}`)
 	})
 
@@ -142,8 +172,8 @@ func TestListTasks(t *testing.T) {
 	}
 
 	want := []Task{
-		{ID: 1, Name: "Task 1"},
-		{ID: 2, Name: "Task 2"},
+		{GID: "1", Name: "Task 1"},
+		{GID: "2", Name: "Task 2"},
 	}
 
 	if !reflect.DeepEqual(tasks, want) {
@@ -151,6 +181,52 @@ func TestListTasks(t *testing.T) {
 	}
 }
 
+func TestIterProjectTasks(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var called int
+	mux.HandleFunc("/projects/7/tasks", func(w http.ResponseWriter, r *http.Request) {
+		called++
+		switch r.URL.Query().Get("offset") {
+		case "":
+			fmt.Fprint(w, `{"data":[{"gid":"1"},{"gid":"2"}],"next_page":{"offset":"o2"}}`)
+		case "o2":
+			fmt.Fprint(w, `{"data":[{"gid":"3"}],"next_page":{"offset":"o3"}}`)
+		case "o3":
+			fmt.Fprint(w, `{"data":[{"gid":"4"}],"next_page":null}`)
+		}
+	})
+
+	it := client.IterProjectTasks("7", nil)
+	var got []GID
+	for it.Next(context.Background()) {
+		got = append(got, it.Value().GID)
+		if it.Value().GID == "3" {
+			break
+		}
+	}
+	if err := it.Err(); err != nil {
+		t.Errorf("IterProjectTasks returned error: %v", err)
+	}
+	if want := []GID{"1", "2", "3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("IterProjectTasks returned %v, want %v", got, want)
+	}
+	testCalled(t, called, 2)
+	if it.Offset() != "o2" || it.NextOffset() != "o3" {
+		t.Errorf("Offset() = %q, NextOffset() = %q, want %q, %q", it.Offset(), it.NextOffset(), "o2", "o3")
+	}
+
+	resumed := client.IterProjectTasks("7", &Filter{Offset: it.NextOffset()})
+	got = nil
+	for resumed.Next(context.Background()) {
+		got = append(got, resumed.Value().GID)
+	}
+	if want := []GID{"4"}; !reflect.DeepEqual(got, want) || resumed.NextOffset() != "" {
+		t.Errorf("resumed IterProjectTasks returned %v, want %v", got, want)
+	}
+}
+
 func TestUpdateTask(t *testing.T) {
 	setup()
 	defer teardown()
@@ -165,12 +241,12 @@ func TestUpdateTask(t *testing.T) {
 		if err != nil {
 			t.Fatalf("error reading request body: %v", err)
 		}
-		want := `{"data":{"notes":"updated notes","custom_fields":[{"id":123}]}}`
+		want := `{"data":{"notes":"updated notes","custom_fields":{"123":"High"}}}`
 		if !reflect.DeepEqual(string(b), want) {
 			t.Errorf("handler received request body %+v, want %+v", string(b), want)
 		}
 
-		fmt.Fprint(w, `{"data":{"id":1,"notes":"updated notes","custom_fields":[{"id":123}]}}`)
+		fmt.Fprint(w, `{"data":{"gid":"1","notes":"updated notes","custom_fields":[{"gid":"123"}]}}`)
 	})
 
 	// TODO: Add this to package API, like go-github, maybe? Think about it first.
@@ -179,12 +255,12 @@ func TestUpdateTask(t *testing.T) {
 	// to store v and returns a pointer to it.
 	String := func(v string) *string { return &v }
 
-	task, err := client.UpdateTask(context.Background(), 1, TaskUpdate{Notes: String("updated notes"), CustomFields: []CustomField{{ID: 123}}}, nil)
+	task, err := client.UpdateTask(context.Background(), "1", TaskUpdate{Notes: String("updated notes"), CustomFields: map[GID]interface{}{"123": "High"}}, nil)
 	if err != nil {
 		t.Errorf("UpdateTask returned error: %v", err)
 	}
 
-	want := Task{ID: 1, Notes: "updated notes", CustomFields: []CustomField{{ID: 123}}}
+	want := Task{GID: "1", Notes: "updated notes", CustomFields: []CustomField{{GID: "123"}}}
 	if !reflect.DeepEqual(task, want) {
 		t.Errorf("UpdateTask \nreturned :\n%+v,\nwant :\n %+v", task, want)
 	}
@@ -196,8 +272,8 @@ func TestListTags(t *testing.T) {
 
 	mux.HandleFunc("/tags", func(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprint(w, `{"data":[
-			{"id":1,"name":"Tag 1"},
-			{"id":2,"name":"Tag 2"}
+			{"gid":"1","name":"Tag 1"},
+			{"gid":"2","name":"Tag 2"}
 		]}`)
 	})
 
@@ -207,8 +283,8 @@ func TestListTags(t *testing.T) {
 	}
 
 	want := []Tag{
-		{ID: 1, Name: "Tag 1"},
-		{ID: 2, Name: "Tag 2"},
+		{GID: "1", Name: "Tag 1"},
+		{GID: "2", Name: "Tag 2"},
 	}
 
 	if !reflect.DeepEqual(tags, want) {
@@ -230,6 +306,64 @@ func TestUnauthorized(t *testing.T) {
 	}
 }
 
+func TestErrorKinds(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/tasks/404", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req-1")
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"errors":[{"message":"task: Unknown object: 404","help":"For more information on API status codes..."}]}`)
+	})
+	mux.HandleFunc("/tasks/402", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusPaymentRequired)
+		fmt.Fprint(w, `{"errors":[{"message":"This feature is only available to premium users."}]}`)
+	})
+	mux.HandleFunc("/tasks/429", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprint(w, `{"errors":[{"message":"Rate limit enforced"}]}`)
+	})
+	mux.HandleFunc("/tasks/502", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		fmt.Fprint(w, `<html>Bad Gateway</html>`)
+	})
+	mux.HandleFunc("/tasks/200", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html>OK</html>`)
+	})
+
+	_, err := client.GetTask(context.Background(), "404", nil)
+	if !IsNotFound(err) || IsRetryable(err) {
+		t.Errorf("GetTask returned %v, want a not found error", err)
+	}
+	var errs *Errors
+	if !errors.As(err, &errs) || errs.Code != 404 || errs.RequestID != "req-1" || len(errs.Errors) != 1 || errs.Errors[0].Help == "" {
+		t.Errorf("GetTask returned %+v", errs)
+	}
+
+	_, err = client.GetTask(context.Background(), "402", nil)
+	if !IsPaymentRequired(err) || IsRetryable(err) {
+		t.Errorf("GetTask returned %v, want a payment required error", err)
+	}
+
+	_, err = client.GetTask(context.Background(), "429", nil)
+	var rl *RateLimitError
+	if !errors.As(err, &rl) || rl.RetryAfter != 30*time.Second || !IsRetryable(err) {
+		t.Errorf("GetTask returned %v, want a rate limit error", err)
+	}
+
+	_, err = client.GetTask(context.Background(), "502", nil)
+	if !IsServerError(err) || !errors.As(err, &errs) || string(errs.Body) != `<html>Bad Gateway</html>` {
+		t.Errorf("GetTask returned %v, want a server error with the raw body", err)
+	}
+
+	_, err = client.GetTask(context.Background(), "200", nil)
+	var de *DecodeError
+	if !errors.As(err, &de) || de.Code != 200 || string(de.Body) != `<html>OK</html>` {
+		t.Errorf("GetTask returned %v, want a decode error", err)
+	}
+}
+
 func TestCreateTask(t *testing.T) {
 	setup()
 	defer teardown()
@@ -245,20 +379,11 @@ func TestCreateTask(t *testing.T) {
 		if err != nil {
 			t.Fatalf("error reading request body: %v", err)
 		}
-		fmt.Printf("\nquery : %v\n", string(b))
-		values, err := url.ParseQuery(string(b))
-		if err != nil {
-			t.Fatalf("error parsing body: %v", err)
-		}
-		want := url.Values{
-
-			"key1": []string{"value1"},
-			"key2": []string{"value2"},
-		}
-		if !reflect.DeepEqual(values, want) {
-			t.Errorf("invalid body received %v", values)
+		want := `{"data":{"key1":"value1","key2":"value2"}}`
+		if string(b) != want {
+			t.Errorf("handler received request body %+v, want %+v", string(b), want)
 		}
-		fmt.Fprint(w, `{"data":{"id":1,"notes":"updated notes"}}`)
+		fmt.Fprint(w, `{"data":{"gid":"1","notes":"updated notes"}}`)
 	})
 
 	task, err := client.CreateTask(context.Background(), map[string]interface{}{
@@ -270,7 +395,7 @@ func TestCreateTask(t *testing.T) {
 		t.Errorf("CreateTask returned error: %v", err)
 	}
 
-	want := Task{ID: 1, Notes: "updated notes"}
+	want := Task{GID: "1", Notes: "updated notes"}
 	if !reflect.DeepEqual(task, want) {
 		t.Errorf("CreateTask returned %+v, want %+v", task, want)
 	}
@@ -281,17 +406,17 @@ func TestGetWebhook(t *testing.T) {
 	defer teardown()
 
 	mux.HandleFunc("/webhooks/1", func(w http.ResponseWriter, r *http.Request) {
-		fmt.Fprint(w, `{"data":{"id":1,"resource":{"id":5,"name":"Project X"},"target":"http://site.com/webhook/666","active":true}}`)
+		fmt.Fprint(w, `{"data":{"gid":"1","resource":{"gid":"5","name":"Project X"},"target":"http://site.com/webhook/666","active":true}}`)
 	})
 
-	webhook, err := client.GetWebhook(context.Background(), 1)
+	webhook, err := client.GetWebhook(context.Background(), "1")
 	if err != nil {
 		t.Errorf("GetWebhook returned error: %v", err)
 	}
 
 	want := Webhook{
-		ID:       1,
-		Resource: Resource{ID: 5, Name: "Project X"},
+		GID:      "1",
+		Resource: Resource{GID: "5", Name: "Project X"},
 		Target:   "http://site.com/webhook/666",
 		Active:   true,
 	}
@@ -306,7 +431,7 @@ func TestListWebhooks(t *testing.T) {
 	defer teardown()
 
 	mux.HandleFunc("/webhooks", func(w http.ResponseWriter, r *http.Request) {
-		fmt.Fprint(w, `{"data":[{"id":1,"resource":{"id":5,"name":"Project X"},"target":"http://site.com/webhook/666","active":true},{"id":2,"resource":{"id":6,"name":"Project Y"},"target":"http://site.com/webhook/555","active":true}]}`)
+		fmt.Fprint(w, `{"data":[{"gid":"1","resource":{"gid":"5","name":"Project X"},"target":"http://site.com/webhook/666","active":true},{"gid":"2","resource":{"gid":"6","name":"Project Y"},"target":"http://site.com/webhook/555","active":true}]}`)
 	})
 
 	webhooks, err := client.ListWebhooks(context.Background(), nil)
@@ -316,14 +441,14 @@ func TestListWebhooks(t *testing.T) {
 
 	want := []Webhook{
 		{
-			ID:       1,
-			Resource: Resource{ID: 5, Name: "Project X"},
+			GID:      "1",
+			Resource: Resource{GID: "5", Name: "Project X"},
 			Target:   "http://site.com/webhook/666",
 			Active:   true,
 		},
 		{
-			ID:       2,
-			Resource: Resource{ID: 6, Name: "Project Y"},
+			GID:      "2",
+			Resource: Resource{GID: "6", Name: "Project Y"},
 			Target:   "http://site.com/webhook/555",
 			Active:   true,
 		},
@@ -334,6 +459,27 @@ func TestListWebhooks(t *testing.T) {
 	}
 }
 
+func TestListWebhooksPages(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/webhooks", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("offset") == "" {
+			fmt.Fprint(w, `{"data":[{"gid":"1"}],"next_page":{"offset":"o2"}}`)
+			return
+		}
+		fmt.Fprint(w, `{"data":[{"gid":"2"}]}`)
+	})
+
+	webhooks, err := client.ListWebhooks(context.Background(), nil)
+	if err != nil {
+		t.Errorf("ListWebhooks returned error: %v", err)
+	}
+	if want := []Webhook{{GID: "1"}, {GID: "2"}}; !reflect.DeepEqual(webhooks, want) {
+		t.Errorf("ListWebhooks returned %+v, want %+v", webhooks, want)
+	}
+}
+
 func TestCreateWebhook(t *testing.T) {
 	setup()
 	defer teardown()
@@ -344,40 +490,40 @@ func TestCreateWebhook(t *testing.T) {
 	mux.HandleFunc("/webhooks", func(w http.ResponseWriter, r *http.Request) {
 		called++
 		testMethod(t, r, "POST")
-		testHeader(t, r, "Content-Type", "application/x-www-form-urlencoded")
+		testHeader(t, r, "Content-Type", "application/json")
 		b, err := ioutil.ReadAll(r.Body)
 		if err != nil {
 			t.Fatalf("error reading request body: %v", err)
 		}
-		values, err := url.ParseQuery(string(b))
-		if err != nil {
-			t.Fatalf("error parsing body: %v", err)
-		}
-		want := url.Values{
-			"resource": []string{"123"},
-			"target":   []string{"http://server.com/webhook"},
-		}
-		if !reflect.DeepEqual(values, want) {
-			t.Errorf("invalid body received %v", values)
+		want := `{"data":{"filters":[{"resource_type":"task","action":"changed","fields":["due_on"]}],"resource":"123","target":"http://server.com/webhook"}}`
+		if string(b) != want {
+			t.Errorf("handler received request body %+v, want %+v", string(b), want)
 		}
-		fmt.Fprint(w, `{"data":{"id":3,"resource":{"id":123,"name":"Project Z"},"target":"http://server.com/webhook","active":true}}`)
+		fmt.Fprint(w, `{"data":{"gid":"3","resource":{"gid":"123","name":"Project Z"},"target":"http://server.com/webhook","active":true,"last_failure_at":"2020-01-02T03:04:05Z","last_failure_content":"500 Internal Server Error"}}`)
 	})
 
-	webhook, err := client.CreateWebhook(context.Background(), 123, "http://server.com/webhook")
+	webhook, err := client.CreateWebhook(context.Background(), "123", "http://server.com/webhook",
+		WebhookFilter{ResourceType: "task", Action: "changed", Fields: []string{"due_on"}})
 
 	if err != nil {
 		t.Errorf("CreateWebhook returned error: %v", err)
 	}
 
 	want := Webhook{
-		ID:       3,
-		Resource: Resource{ID: 123, Name: "Project Z"},
-		Target:   "http://server.com/webhook",
-		Active:   true,
+		GID:                "3",
+		Resource:           Resource{GID: "123", Name: "Project Z"},
+		Target:             "http://server.com/webhook",
+		Active:             true,
+		LastFailureAt:      time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		LastFailureContent: "500 Internal Server Error",
 	}
 	if !reflect.DeepEqual(webhook, want) {
 		t.Errorf("CreateWebhook returned %+v, want %+v", webhook, want)
 	}
+
+	if _, err := client.CreateWorkspaceWebhook(context.Background(), "100", "http://server.com/webhook"); err == nil {
+		t.Errorf("CreateWorkspaceWebhook without filters expected an error")
+	}
 }
 
 func TestDeleteWebhook(t *testing.T) {
@@ -393,13 +539,1490 @@ func TestDeleteWebhook(t *testing.T) {
 		fmt.Fprint(w, `{"data":{}}`)
 	})
 
-	err := client.DeleteWebhook(context.Background(), 123)
+	err := client.DeleteWebhook(context.Background(), "123")
 
 	if err != nil {
 		t.Errorf("DeleteWebhook returned error: %v", err)
 	}
 }
 
+func TestRetryRateLimited(t *testing.T) {
+	setup()
+	defer teardown()
+	client.Retry = &RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+	var called int
+	defer func() { testCalled(t, called, 3) }()
+	mux.HandleFunc("/tags", func(w http.ResponseWriter, r *http.Request) {
+		called++
+		switch called {
+		case 1:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, `{"errors":[{"message":"Rate limit enforced"}]}`)
+		case 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			fmt.Fprint(w, `{"data":[{"gid":"1","name":"Tag 1"}]}`)
+		}
+	})
+
+	tags, err := client.ListTags(context.Background(), nil)
+	if err != nil {
+		t.Errorf("ListTags returned error: %v", err)
+	}
+	if len(tags) != 1 {
+		t.Errorf("ListTags returned %+v, want 1 tag", tags)
+	}
+}
+
+func TestRetryNonIdempotent(t *testing.T) {
+	setup()
+	defer teardown()
+	client.Retry = &RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+	var called int
+	var bodies []string
+	mux.HandleFunc("/tasks", func(w http.ResponseWriter, r *http.Request) {
+		called++
+		b, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		if called == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		fmt.Fprint(w, `{"data":{"gid":"1"}}`)
+	})
+
+	if _, err := client.CreateTask(context.Background(), map[string]interface{}{"name": "n"}, nil); err == nil {
+		t.Errorf("CreateTask expected an error when POST retries are disabled")
+	}
+	testCalled(t, called, 1)
+
+	called, bodies = 0, nil
+	client.Retry.RetryNonIdempotent = true
+	if _, err := client.CreateTask(context.Background(), map[string]interface{}{"name": "n"}, nil); err != nil {
+		t.Errorf("CreateTask returned error: %v", err)
+	}
+	testCalled(t, called, 2)
+	if len(bodies) == 2 && bodies[0] != bodies[1] {
+		t.Errorf("retried body %q, want %q", bodies[1], bodies[0])
+	}
+}
+
+func TestRetryRespectsDeadline(t *testing.T) {
+	setup()
+	defer teardown()
+	client.Retry = &RetryPolicy{MaxAttempts: 5, MinBackoff: time.Millisecond}
+
+	var called int
+	mux.HandleFunc("/tags", func(w http.ResponseWriter, r *http.Request) {
+		called++
+		w.Header().Set("Retry-After", "10")
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprint(w, `{"errors":[{"message":"Rate limit enforced"}]}`)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	start := time.Now()
+	if _, err := client.ListTags(ctx, nil); err == nil {
+		t.Errorf("ListTags expected an error")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("ListTags waited %v past a Retry-After longer than the deadline", elapsed)
+	}
+	testCalled(t, called, 1)
+}
+
+func TestRetryAfterBeyondMaxBackoff(t *testing.T) {
+	setup()
+	defer teardown()
+	client.Retry = &RetryPolicy{MaxAttempts: 5, MinBackoff: time.Millisecond, MaxBackoff: 30 * time.Second}
+
+	var called int
+	mux.HandleFunc("/tags", func(w http.ResponseWriter, r *http.Request) {
+		called++
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprint(w, `{"errors":[{"message":"Rate limit enforced"}]}`)
+	})
+
+	start := time.Now()
+	_, err := client.ListTags(context.Background(), nil)
+	var rl *RateLimitError
+	if !errors.As(err, &rl) || rl.RetryAfter != 60*time.Second {
+		t.Errorf("ListTags returned %v, want a rate limit error asking for 60s", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("ListTags waited %v instead of giving up", elapsed)
+	}
+	testCalled(t, called, 1)
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+		ok   bool
+	}{
+		{"", 0, false},
+		{"3", 3 * time.Second, true},
+		{"-1", 0, true},
+		{"Mon, 02 Jan 2006 15:04:05 GMT", 0, true},
+		{"soon", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := retryAfter(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("retryAfter(%q) = %v, %v, want %v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	setup()
+	defer teardown()
+	client = NewClient(nil, WithRateLimit(50, 1))
+	client.BaseURL, _ = url.Parse(server.URL)
+
+	mux.HandleFunc("/tags", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data":[]}`)
+	})
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := client.ListTags(context.Background(), nil); err != nil {
+			t.Fatalf("ListTags returned error: %v", err)
+		}
+	}
+	// The first request uses the burst, the next two wait 20ms each. The
+	// waits are computed in floating point, so allow 1ms for rounding.
+	want := 2*time.Second/50 - time.Millisecond
+	if elapsed := time.Since(start); elapsed < want {
+		t.Errorf("3 requests at 50 rps took %v, want at least %v", elapsed, want)
+	}
+	if st := client.LimiterState(); st.LocalThrottled != 2 || st.Rate != 50 || st.Burst != 1 {
+		t.Errorf("LimiterState returned %+v", st)
+	}
+}
+
+func TestMaxInFlight(t *testing.T) {
+	setup()
+	defer teardown()
+	client = NewClient(nil, WithMaxInFlight(1))
+	client.BaseURL, _ = url.Parse(server.URL)
+
+	entered := make(chan struct{})
+	unblock := make(chan struct{})
+	mux.HandleFunc("/tags", func(w http.ResponseWriter, r *http.Request) {
+		entered <- struct{}{}
+		<-unblock
+		fmt.Fprint(w, `{"data":[]}`)
+	})
+
+	done := make(chan error)
+	go func() {
+		_, err := client.ListTags(context.Background(), nil)
+		done <- err
+	}()
+	<-entered
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := client.ListTags(ctx, nil); err != context.DeadlineExceeded {
+		t.Errorf("ListTags returned %v while the in-flight slot was taken, want %v", err, context.DeadlineExceeded)
+	}
+	if st := client.LimiterState(); st.InFlight != 1 || st.MaxInFlight != 1 || st.LocalThrottled != 1 {
+		t.Errorf("LimiterState returned %+v", st)
+	}
+
+	close(unblock)
+	if err := <-done; err != nil {
+		t.Errorf("ListTags returned error: %v", err)
+	}
+	if st := client.LimiterState(); st.InFlight != 0 {
+		t.Errorf("LimiterState.InFlight = %d after the response was read, want 0", st.InFlight)
+	}
+}
+
+func TestRemoteThrottle(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/tags", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprint(w, `{"errors":[{"message":"Rate limit enforced"}]}`)
+	})
+
+	client.ListTags(context.Background(), nil)
+	if st := client.LimiterState(); st.RemoteThrottled != 1 || st.LastRemoteThrottle.IsZero() || st.LocalThrottled != 0 {
+		t.Errorf("LimiterState returned %+v", st)
+	}
+}
+
+func eventsHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Query().Get("sync") {
+	case "", "old":
+		w.WriteHeader(http.StatusPreconditionFailed)
+		fmt.Fprint(w, `{"errors":[{"message":"Sync token invalid or too old"}],"sync":"s1"}`)
+	case "s1":
+		fmt.Fprint(w, `{"data":[{"action":"changed","resource":{"gid":"1","resource_type":"task"},"change":{"field":"name","action":"changed","new_value":"N"}}],"sync":"s2","has_more":true}`)
+	case "s2":
+		fmt.Fprint(w, `{"data":[{"action":"added","resource":{"gid":"2","resource_type":"story"},"parent":{"gid":"1","resource_type":"task"}}],"sync":"s3","has_more":false}`)
+	default:
+		fmt.Fprint(w, `{"data":[],"sync":"s3"}`)
+	}
+}
+
+func TestGetEvents(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/events", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("resource"); got != "7" {
+			t.Errorf("resource = %q, want %q", got, "7")
+		}
+		eventsHandler(w, r)
+	})
+
+	events, sync, err := client.GetEvents(context.Background(), "7", "")
+	if err != nil || len(events) != 0 || sync != "s1" {
+		t.Errorf("GetEvents without token returned %v, %q, %v", events, sync, err)
+	}
+
+	events, sync, err = client.GetEvents(context.Background(), "7", "s1")
+	if err != nil {
+		t.Errorf("GetEvents returned error: %v", err)
+	}
+	if len(events) != 2 || sync != "s3" {
+		t.Fatalf("GetEvents returned %+v, %q, want 2 events and %q", events, sync, "s3")
+	}
+	if events[0].Change == nil || events[0].Change.Field != "name" || events[1].Parent.GID != "1" {
+		t.Errorf("GetEvents returned %+v", events)
+	}
+
+	_, sync, err = client.GetEvents(context.Background(), "7", "old")
+	if err != ErrSyncTokenExpired || sync != "s1" {
+		t.Errorf("GetEvents with an expired token returned %q, %v", sync, err)
+	}
+}
+
+func TestWatchEvents(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/events", eventsHandler)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	syncs := make(chan string, 10)
+	ch := client.WatchEvents(ctx, "7", &WatchOptions{
+		Interval: time.Millisecond,
+		OnSync:   func(token string) { syncs <- token },
+	})
+
+	var got []GID
+	for e := range ch {
+		got = append(got, e.Resource.GID)
+		if len(got) == 2 {
+			cancel()
+		}
+	}
+	if want := []GID{"1", "2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("WatchEvents delivered %v, want %v", got, want)
+	}
+	if s := <-syncs; s != "s1" {
+		t.Errorf("first OnSync token = %q, want %q", s, "s1")
+	}
+}
+
+func TestSyncTokenStores(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "sync.json")
+	for _, store := range []SyncTokenStore{NewMemorySyncTokenStore(), NewFileSyncTokenStore(path)} {
+		if token, err := store.LoadSyncToken(ctx, "1"); err != nil || token != "" {
+			t.Errorf("%T.LoadSyncToken returned %q, %v, want empty token", store, token, err)
+		}
+		store.SaveSyncToken(ctx, "1", "a")
+		store.SaveSyncToken(ctx, "2", "b")
+		store.SaveSyncToken(ctx, "1", "c")
+		if token, err := store.LoadSyncToken(ctx, "1"); err != nil || token != "c" {
+			t.Errorf("%T.LoadSyncToken returned %q, %v, want %q", store, token, err, "c")
+		}
+	}
+
+	reopened := NewFileSyncTokenStore(path)
+	if token, err := reopened.LoadSyncToken(ctx, "2"); err != nil || token != "b" {
+		t.Errorf("LoadSyncToken after reopening returned %q, %v, want %q", token, err, "b")
+	}
+}
+
+func TestEventConsumer(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/events", eventsHandler)
+
+	ctx := context.Background()
+	store := NewMemorySyncTokenStore()
+	var handled []GID
+	var expired int
+	ec := &EventConsumer{
+		Client:   client,
+		Store:    store,
+		Resource: "7",
+		Handle: func(ctx context.Context, events []Event) error {
+			for _, e := range events {
+				handled = append(handled, e.Resource.GID)
+			}
+			return nil
+		},
+		OnExpired: func(ctx context.Context, resourceGID GID) error {
+			expired++
+			return nil
+		},
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := ec.Poll(ctx); err != nil {
+			t.Fatalf("Poll returned error: %v", err)
+		}
+	}
+	if token, _ := store.LoadSyncToken(ctx, "7"); token != "s3" {
+		t.Errorf("saved token = %q, want %q", token, "s3")
+	}
+	if want := []GID{"1", "2"}; !reflect.DeepEqual(handled, want) {
+		t.Errorf("handled %v, want %v", handled, want)
+	}
+
+	store.SaveSyncToken(ctx, "7", "old")
+	if err := ec.Poll(ctx); err != nil {
+		t.Fatalf("Poll returned error: %v", err)
+	}
+	if token, _ := store.LoadSyncToken(ctx, "7"); token != "s1" || expired != 1 {
+		t.Errorf("after expiry saved token = %q and OnExpired called %d times", token, expired)
+	}
+
+	ec.Handle = func(ctx context.Context, events []Event) error { return errors.New("boom") }
+	if err := ec.Poll(ctx); err == nil {
+		t.Errorf("Poll expected the Handle error")
+	}
+	if token, _ := store.LoadSyncToken(ctx, "7"); token != "s1" {
+		t.Errorf("saved token = %q after a failed Handle, want %q", token, "s1")
+	}
+}
+
+func TestWebhookHandler(t *testing.T) {
+	secrets := NewMemoryWebhookSecretStore()
+	var received []Event
+	h := NewWebhookHandler(secrets, func(ctx context.Context, events []Event) error {
+		received = append(received, events...)
+		return nil
+	})
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	req, _ := http.NewRequest("POST", srv.URL+"/hooks/1", nil)
+	req.Header.Set("X-Hook-Secret", "s3cr3t")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("handshake returned error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("X-Hook-Secret") != "s3cr3t" {
+		t.Errorf("handshake responded %d with secret %q", resp.StatusCode, resp.Header.Get("X-Hook-Secret"))
+	}
+	if secret, _ := secrets.LoadWebhookSecret(context.Background(), "/hooks/1"); secret != "s3cr3t" {
+		t.Errorf("stored secret = %q, want %q", secret, "s3cr3t")
+	}
+
+	body := `{"events":[{"action":"changed","resource":{"gid":"1","resource_type":"task"}}]}`
+	mac := hmac.New(sha256.New, []byte("s3cr3t"))
+	mac.Write([]byte(body))
+	signature := hex.EncodeToString(mac.Sum(nil))
+
+	tests := []struct {
+		signature string
+		want      int
+	}{
+		{signature, http.StatusOK},
+		{"", http.StatusUnauthorized},
+		{hex.EncodeToString([]byte("forged")), http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest("POST", srv.URL+"/hooks/1", strings.NewReader(body))
+		req.Header.Set("X-Hook-Signature", tt.signature)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("delivery returned error: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.want {
+			t.Errorf("delivery signed %q responded %d, want %d", tt.signature, resp.StatusCode, tt.want)
+		}
+	}
+	if len(received) != 1 || received[0].Resource.GID != "1" {
+		t.Errorf("OnEvents received %+v, want the one signed event", received)
+	}
+
+	// A second handshake must not replace the secret of the webhook.
+	req, _ = http.NewRequest("POST", srv.URL+"/hooks/1", nil)
+	req.Header.Set("X-Hook-Secret", "forged")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("handshake returned error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden || resp.Header.Get("X-Hook-Secret") != "" {
+		t.Errorf("second handshake responded %d with secret %q, want 403", resp.StatusCode, resp.Header.Get("X-Hook-Secret"))
+	}
+	req, _ = http.NewRequest("POST", srv.URL+"/hooks/1", strings.NewReader(body))
+	req.Header.Set("X-Hook-Signature", signature)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("delivery returned error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || len(received) != 2 {
+		t.Errorf("delivery signed with the original secret responded %d", resp.StatusCode)
+	}
+
+	// Once forgotten, the secret can be replaced.
+	secrets.SaveWebhookSecret(context.Background(), "/hooks/1", "")
+	req, _ = http.NewRequest("POST", srv.URL+"/hooks/1", nil)
+	req.Header.Set("X-Hook-Secret", "n3w")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("handshake returned error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("handshake after forgetting the secret responded %d, want 200", resp.StatusCode)
+	}
+}
+
+func TestEventRouter(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/tasks/1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data":{"gid":"1","name":"Task 1"}}`)
+	})
+
+	var calls []string
+	record := func(name string) EventHandler {
+		return func(ctx context.Context, e *RoutedEvent) error {
+			if e.Task == nil || e.Task.Name != "Task 1" {
+				t.Errorf("%s received task %+v, want hydrated Task 1", name, e.Task)
+			}
+			calls = append(calls, name)
+			return nil
+		}
+	}
+	r := NewEventRouter(client)
+	r.HydrateTasks = true
+	r.Use(RecoverEvents(), DedupeEvents(10))
+	r.OnTaskChanged(record("name"), "name")
+	r.OnTaskChanged(record("any"))
+	r.OnTaskRemovedFromProject(record("removed"))
+	r.OnStoryAdded(record("story"))
+	r.OnProjectChanged(func(ctx context.Context, e *RoutedEvent) error { panic("boom") })
+
+	task := Resource{GID: "1", ResourceType: "task"}
+	events := []Event{
+		{Action: "changed", Resource: task, Change: &EventChange{Field: "name"}},
+		{Action: "changed", Resource: task, Change: &EventChange{Field: "notes"}},
+		{Action: "changed", Resource: task, Change: &EventChange{Field: "notes"}},
+		{Action: "removed", Resource: task, Parent: Resource{GID: "7", ResourceType: "project"}},
+		{Action: "removed", Resource: task, Parent: Resource{GID: "8", ResourceType: "tag"}},
+		{Action: "added", Resource: Resource{GID: "2", ResourceType: "story"}, Parent: task},
+	}
+	if err := r.Dispatch(context.Background(), events); err != nil {
+		t.Errorf("Dispatch returned error: %v", err)
+	}
+	if want := []string{"name", "any", "any", "removed", "story"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("Dispatch called %v, want %v", calls, want)
+	}
+
+	err := r.Dispatch(context.Background(), []Event{{Action: "changed", Resource: Resource{GID: "7", ResourceType: "project"}}})
+	if err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("Dispatch returned %v, want the recovered panic", err)
+	}
+}
+
+func TestWebhookReconciler(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var created []string
+	var deleted []string
+	mux.HandleFunc("/webhooks", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			b, _ := ioutil.ReadAll(r.Body)
+			created = append(created, string(b))
+			fmt.Fprint(w, `{"data":{"gid":"9"}}`)
+			return
+		}
+		if got := r.URL.Query().Get("workspace"); got != "100" {
+			t.Errorf("workspace = %q, want %q", got, "100")
+		}
+		fmt.Fprint(w, `{"data":[
+			{"gid":"1","resource":{"gid":"10"},"target":"https://a","active":true},
+			{"gid":"2","resource":{"gid":"20"},"target":"https://b","active":false},
+			{"gid":"3","resource":{"gid":"10"},"target":"https://a","active":true},
+			{"gid":"4","resource":{"gid":"30"},"target":"https://c","active":true},
+			{"gid":"5","resource":{"gid":"50"},"target":"https://other","active":true}
+		]}`)
+	})
+	mux.HandleFunc("/webhooks/", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "DELETE")
+		deleted = append(deleted, strings.TrimPrefix(r.URL.Path, "/webhooks/"))
+		fmt.Fprint(w, `{"data":{}}`)
+	})
+
+	rec := &WebhookReconciler{
+		Client:    client,
+		Workspace: "100",
+		Owns:      func(w Webhook) bool { return w.Target != "https://other" },
+		DryRun:    true,
+	}
+	desired := []WebhookSpec{
+		{Resource: "10", Target: "https://a"},
+		{Resource: "20", Target: "https://b"},
+		{Resource: "40", Target: "https://d", Filters: []WebhookFilter{{ResourceType: "task", Action: "changed", Fields: []string{"name"}}}},
+	}
+	plan, err := rec.Reconcile(context.Background(), desired)
+	if err != nil {
+		t.Fatalf("Reconcile returned error: %v", err)
+	}
+	if len(created) != 0 || len(deleted) != 0 {
+		t.Errorf("dry run created %v and deleted %v", created, deleted)
+	}
+	want := `delete   webhook=3 resource=10 target=https://a (duplicate)
+recreate webhook=2 resource=20 target=https://b filters=none (inactive)
+create   resource=40 target=https://d filters=task.changed[name] (missing)
+delete   webhook=4 resource=30 target=https://c (not desired)
+1 to create, 1 to recreate, 2 to delete, 1 unchanged (dry run)
+`
+	if got := plan.String(); got != want {
+		t.Errorf("plan:\n%s\nwant:\n%s", got, want)
+	}
+
+	rec.DryRun = false
+	if _, err := rec.Reconcile(context.Background(), desired); err != nil {
+		t.Fatalf("Reconcile returned error: %v", err)
+	}
+	wantCreated := []string{
+		`{"data":{"resource":"20","target":"https://b"}}`,
+		`{"data":{"filters":[{"resource_type":"task","action":"changed","fields":["name"]}],"resource":"40","target":"https://d"}}`,
+	}
+	if !reflect.DeepEqual(created, wantCreated) {
+		t.Errorf("Reconcile created %v, want %v", created, wantCreated)
+	}
+	if want := []string{"3", "2", "4"}; !reflect.DeepEqual(deleted, want) {
+		t.Errorf("Reconcile deleted %v, want %v", deleted, want)
+	}
+}
+
+func TestWebhookReconcilerRecreate(t *testing.T) {
+	setup()
+	defer teardown()
+
+	secrets := NewMemoryWebhookSecretStore()
+	h := NewWebhookHandler(secrets, nil)
+	hooks := httptest.NewServer(h)
+	defer hooks.Close()
+	target := hooks.URL + "/hooks/b"
+	secrets.SaveWebhookSecret(context.Background(), "/hooks/b", "old")
+
+	var calls []string
+	mux.HandleFunc("/webhooks", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			calls = append(calls, "create")
+			// Asana answers only once the target completed the handshake.
+			req, _ := http.NewRequest("POST", target, nil)
+			req.Header.Set("X-Hook-Secret", "new")
+			resp, err := http.DefaultClient.Do(req)
+			if err == nil {
+				resp.Body.Close()
+			}
+			if err != nil || resp.StatusCode != http.StatusOK {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"errors":[{"message":"handshake failed"}]}`)
+				return
+			}
+			fmt.Fprint(w, `{"data":{"gid":"9","active":true}}`)
+			return
+		}
+		fmt.Fprintf(w, `{"data":[
+			{"gid":"2","resource":{"gid":"20"},"target":%q,"active":false},
+			{"gid":"5","resource":{"gid":"50"},"target":"https://other","active":true}
+		]}`, target)
+	})
+	mux.HandleFunc("/webhooks/", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "DELETE")
+		calls = append(calls, "delete "+strings.TrimPrefix(r.URL.Path, "/webhooks/"))
+		fmt.Fprint(w, `{"data":{}}`)
+	})
+
+	rec := &WebhookReconciler{Client: client, Workspace: "100", Secrets: secrets}
+	plan, err := rec.Reconcile(context.Background(), []WebhookSpec{{Resource: "20", Target: target}})
+	if err != nil {
+		t.Fatalf("Reconcile returned error: %v\n%s", err, plan)
+	}
+	// The webhook of another integration is not owned by default.
+	if want := []string{"delete 2", "create"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("Reconcile made %v, want %v", calls, want)
+	}
+
+	body := `{"events":[{"action":"changed","resource":{"gid":"1","resource_type":"task"}}]}`
+	mac := hmac.New(sha256.New, []byte("new"))
+	mac.Write([]byte(body))
+	req, _ := http.NewRequest("POST", target, strings.NewReader(body))
+	req.Header.Set("X-Hook-Signature", hex.EncodeToString(mac.Sum(nil)))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("delivery returned error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("delivery of the recreated webhook responded %d, want 200", resp.StatusCode)
+	}
+}
+
+func TestWebhookReconcilerSharedTarget(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var created int
+	mux.HandleFunc("/webhooks", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			created++
+		}
+		fmt.Fprint(w, `{"data":[]}`)
+	})
+
+	desired := []WebhookSpec{
+		{Resource: "10", Target: "https://example.com/hooks/a"},
+		{Resource: "20", Target: "https://example.com/hooks/a"},
+	}
+	rec := &WebhookReconciler{Client: client, Workspace: "100", Secrets: NewMemoryWebhookSecretStore()}
+	if _, err := rec.Reconcile(context.Background(), desired); err == nil || !strings.Contains(err.Error(), "share target") {
+		t.Errorf("Reconcile returned %v, want a shared target error", err)
+	}
+	testCalled(t, created, 0)
+
+	// Without a secret store, the handler is left to tell them apart.
+	rec.Secrets = nil
+	rec.DryRun = true
+	if _, err := rec.Reconcile(context.Background(), desired); err != nil {
+		t.Errorf("Reconcile returned error: %v", err)
+	}
+}
+
+func TestSearchTasks(t *testing.T) {
+	setup()
+	defer teardown()
+
+	newest := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	page := func(from, to int) string {
+		var tasks []string
+		for i := from; i <= to; i++ {
+			createdAt := newest.Add(-time.Duration(i) * time.Second).Format(time.RFC3339)
+			tasks = append(tasks, fmt.Sprintf(`{"gid":"%d","created_at":"%s"}`, i, createdAt))
+		}
+		return `{"data":[` + strings.Join(tasks, ",") + `]}`
+	}
+
+	var called int
+	mux.HandleFunc("/workspaces/1/tasks/search", func(w http.ResponseWriter, r *http.Request) {
+		called++
+		testMethod(t, r, "GET")
+		q := r.URL.Query()
+		want := url.Values{
+			"text":                      {"launch"},
+			"tags.any":                  {"7,8"},
+			"completed":                 {"false"},
+			"custom_fields.5.value":     {"6"},
+			"custom_fields.9.less_than": {"2.5"},
+			"sort_by":                   {"created_at"},
+			"sort_ascending":            {"false"},
+			"opt_fields":                {"name,created_at"},
+			"limit":                     {"100"},
+		}
+		switch called {
+		case 1:
+			if !reflect.DeepEqual(q, want) {
+				t.Errorf("Request query: %v, want %v", q, want)
+			}
+			fmt.Fprint(w, page(1, 100))
+		case 2:
+			if got, want := q.Get("created_at.before"), "2024-05-01T11:58:20.001Z"; got != want {
+				t.Errorf("created_at.before = %q, want %q", got, want)
+			}
+			fmt.Fprint(w, page(100, 101))
+		}
+	})
+
+	completed := false
+	tasks, err := client.SearchTasks(context.Background(), "1", &TaskSearch{
+		Text:      "launch",
+		TagsAny:   []GID{"7", "8"},
+		Completed: &completed,
+		CustomFields: []CustomFieldPredicate{
+			EnumFieldEquals("5", "6"),
+			NumberFieldLessThan("9", 2.5),
+		},
+		OptFields: []string{"name"},
+	})
+	if err != nil {
+		t.Fatalf("SearchTasks returned error: %v", err)
+	}
+	testCalled(t, called, 2)
+	if len(tasks) != 101 || tasks[0].GID != "1" || tasks[100].GID != "101" {
+		t.Errorf("SearchTasks returned %d tasks", len(tasks))
+	}
+}
+
+func TestCustomFieldPredicates(t *testing.T) {
+	q := &TaskSearch{CustomFields: []CustomFieldPredicate{
+		CustomFieldIsSet("1", true),
+		DateFieldEquals("2", "2024-05-01"),
+		DateFieldBefore("3", "2024-06-01"),
+		DateFieldAfter("3", "2024-04-01"),
+		MultiEnumFieldAny("4", "41", "42"),
+		MultiEnumFieldAll("5", "51", "52"),
+		PeopleFieldAny("6", "61"),
+		PeopleFieldAll("7", "71", "72"),
+	}}
+	got, err := q.values()
+	if err != nil {
+		t.Fatalf("values returned error: %v", err)
+	}
+	want := url.Values{
+		"custom_fields.1.is_set": {"true"},
+		"custom_fields.2.value":  {"2024-05-01"},
+		"custom_fields.3.before": {"2024-06-01"},
+		"custom_fields.3.after":  {"2024-04-01"},
+		"custom_fields.4.any":    {"41,42"},
+		"custom_fields.5.all":    {"51,52"},
+		"custom_fields.6.any":    {"61"},
+		"custom_fields.7.all":    {"71,72"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("values returned %v, want %v", got, want)
+	}
+}
+
+func TestSearchTasksTruncated(t *testing.T) {
+	setup()
+	defer teardown()
+
+	// 150 tasks created in the same millisecond, as by a bulk import.
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	mux.HandleFunc("/workspaces/1/tasks/search", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if before := q.Get("created_at.before"); before != "" {
+			b, _ := time.Parse(time.RFC3339Nano, before)
+			if !createdAt.Before(b) {
+				fmt.Fprint(w, `{"data":[]}`)
+				return
+			}
+		}
+		limit, _ := strconv.Atoi(q.Get("limit"))
+		var tasks []string
+		for i := 1; i <= 150 && i <= limit; i++ {
+			tasks = append(tasks, fmt.Sprintf(`{"gid":"%d","created_at":"%s"}`, i, createdAt.Format(time.RFC3339Nano)))
+		}
+		fmt.Fprint(w, `{"data":[`+strings.Join(tasks, ",")+`]}`)
+	})
+
+	tasks, err := client.SearchTasks(context.Background(), "1", nil)
+	if !errors.Is(err, ErrSearchTruncated) {
+		t.Errorf("SearchTasks returned error %v, want ErrSearchTruncated", err)
+	}
+	if len(tasks) != 100 {
+		t.Errorf("SearchTasks returned %d tasks, want the 100 found", len(tasks))
+	}
+}
+
+func TestCustomFieldValues(t *testing.T) {
+	var task Task
+	err := json.Unmarshal([]byte(`{"custom_fields":[
+		{"gid":"1","name":"Estimate","type":"number","precision":2,"number_value":1.5},
+		{"gid":"2","name":"Priority","type":"enum","enum_value":{"gid":"21","name":"High"}},
+		{"gid":"3","name":"Labels","type":"multi_enum","multi_enum_values":[{"gid":"31","name":"ui"},{"gid":"32","name":"api"}]},
+		{"gid":"4","name":"Launch","type":"date","date_value":{"date":"2024-05-01"}},
+		{"gid":"5","name":"Reviewers","type":"people","people_value":[{"gid":"51","name":"Ann"}]},
+		{"gid":"6","name":"Unset","type":"number","number_value":null}
+	]}`), &task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfs := task.CustomFields
+
+	if v, ok := cfs[0].Number(); !ok || v != 1.5 {
+		t.Errorf("Number() = %v, %v, want 1.5, true", v, ok)
+	}
+	if v, ok := cfs[1].Enum(); !ok || v.Name != "High" {
+		t.Errorf("Enum() = %+v, %v", v, ok)
+	}
+	if v := cfs[2].MultiEnum(); len(v) != 2 || v[1].GID != "32" {
+		t.Errorf("MultiEnum() = %+v", v)
+	}
+	if v, ok := cfs[3].Date(); !ok || !v.Equal(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Date() = %v, %v", v, ok)
+	}
+	if v := cfs[4].People(); len(v) != 1 || v[0].GID != "51" {
+		t.Errorf("People() = %+v", v)
+	}
+	if _, ok := cfs[5].Number(); ok {
+		t.Errorf("Number() of an unset field returned ok")
+	}
+
+	for name, want := range map[string]string{
+		"Estimate":  "1.50",
+		"Priority":  "High",
+		"Labels":    "ui, api",
+		"Launch":    "2024-05-01",
+		"Reviewers": "Ann",
+		"Unset":     "",
+	} {
+		if got, err := task.GetCustomFieldValue(name); err != nil || got != want {
+			t.Errorf("GetCustomFieldValue(%q) = %q, %v, want %q", name, got, err, want)
+		}
+	}
+}
+
+func TestCustomFields(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/custom_fields/1", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "PUT")
+		b, _ := ioutil.ReadAll(r.Body)
+		if want := `{"data":{"precision":2}}`; string(b) != want {
+			t.Errorf("Request body: %s, want %s", b, want)
+		}
+		fmt.Fprint(w, `{"data":{"gid":"1","precision":2}}`)
+	})
+	mux.HandleFunc("/custom_fields/1/enum_options", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		b, _ := ioutil.ReadAll(r.Body)
+		if want := `{"data":{"insert_before":"21","name":"Urgent"}}`; string(b) != want {
+			t.Errorf("Request body: %s, want %s", b, want)
+		}
+		fmt.Fprint(w, `{"data":{"gid":"22","name":"Urgent","enabled":true}}`)
+	})
+	mux.HandleFunc("/enum_options/22", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "PUT")
+		b, _ := ioutil.ReadAll(r.Body)
+		if want := `{"data":{"enabled":false}}`; string(b) != want {
+			t.Errorf("Request body: %s, want %s", b, want)
+		}
+		fmt.Fprint(w, `{"data":{"gid":"22","name":"Urgent"}}`)
+	})
+
+	ctx := context.Background()
+	precision := int64(2)
+	if field, err := client.UpdateCustomField(ctx, "1", CustomFieldUpdate{Precision: &precision}, nil); err != nil || field.Precision != 2 {
+		t.Errorf("UpdateCustomField returned %+v, %v", field, err)
+	}
+	option, err := client.CreateEnumOption(ctx, "1", map[string]interface{}{"name": "Urgent", "insert_before": "21"}, nil)
+	if err != nil || option != (CFEnumOptions{GID: "22", Name: "Urgent", Enabled: true}) {
+		t.Errorf("CreateEnumOption returned %+v, %v", option, err)
+	}
+	disabled := false
+	if option, err := client.UpdateEnumOption(ctx, "22", EnumOptionUpdate{Enabled: &disabled}, nil); err != nil || option.Enabled {
+		t.Errorf("UpdateEnumOption returned %+v, %v", option, err)
+	}
+}
+
+func TestEnsureProjectCustomFields(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/projects/1/custom_field_settings", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		fmt.Fprint(w, `{"data":[{"gid":"10","custom_field":{"gid":"2"}}]}`)
+	})
+	var added []string
+	mux.HandleFunc("/projects/1/addCustomFieldSetting", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		b, _ := ioutil.ReadAll(r.Body)
+		added = append(added, string(b))
+		fmt.Fprint(w, `{"data":{"gid":"11","custom_field":{"gid":"3"},"is_important":true}}`)
+	})
+
+	important := true
+	settings, err := client.EnsureProjectCustomFields(context.Background(), "1", []CustomFieldSettingUpdate{
+		{CustomField: "2"},
+		{CustomField: "3", IsImportant: &important, InsertAfter: "2"},
+	})
+	if err != nil {
+		t.Fatalf("EnsureProjectCustomFields returned error: %v", err)
+	}
+	if want := []string{`{"data":{"custom_field":"3","is_important":true,"insert_after":"2"}}`}; !reflect.DeepEqual(added, want) {
+		t.Errorf("Added settings: %v, want %v", added, want)
+	}
+	want := []CustomFieldSetting{{GID: "11", CustomField: CustomField{GID: "3"}, IsImportant: true}}
+	if !reflect.DeepEqual(settings, want) {
+		t.Errorf("EnsureProjectCustomFields returned %+v, want %+v", settings, want)
+	}
+}
+
+func TestGetTaskTree(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/tasks/1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data":{"gid":"1","name":"Release"}}`)
+	})
+	subtasks := map[string]string{
+		"1": `[{"gid":"2","name":"Build"},{"gid":"3","name":"Ship"}]`,
+		"2": `[{"gid":"4","name":"Compile"}]`,
+		"3": `[]`,
+		"4": `[]`,
+	}
+	var mu sync.Mutex
+	var called int
+	for gid, data := range subtasks {
+		data := data
+		mux.HandleFunc("/tasks/"+gid+"/subtasks", func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			called++
+			mu.Unlock()
+			fmt.Fprintf(w, `{"data":%s}`, data)
+		})
+	}
+
+	tree, err := client.GetTaskTree(context.Background(), "1", &TaskTreeOptions{Concurrency: 2})
+	if err != nil {
+		t.Fatalf("GetTaskTree returned error: %v", err)
+	}
+	testCalled(t, called, 4)
+	var got []string
+	tree.Walk(func(task *Task, depth int) error {
+		got = append(got, strings.Repeat("-", depth)+task.Name)
+		return nil
+	})
+	if want := []string{"Release", "-Build", "--Compile", "-Ship"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GetTaskTree returned %v, want %v", got, want)
+	}
+
+	called = 0
+	tree, err = client.GetTaskTree(context.Background(), "1", &TaskTreeOptions{MaxDepth: 1})
+	if err != nil {
+		t.Fatalf("GetTaskTree returned error: %v", err)
+	}
+	testCalled(t, called, 1)
+	if len(tree.Subtasks) != 2 || tree.Subtasks[0].Subtasks != nil {
+		t.Errorf("GetTaskTree with MaxDepth 1 returned %+v", tree)
+	}
+}
+
+func TestSetParent(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/tasks/2/setParent", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		b, _ := ioutil.ReadAll(r.Body)
+		if want := `{"data":{"parent":null}}`; string(b) != want {
+			t.Errorf("Request body: %s, want %s", b, want)
+		}
+		fmt.Fprint(w, `{"data":{"gid":"2"}}`)
+	})
+
+	if _, err := client.SetParent(context.Background(), "2", ParentUpdate{}, nil); err != nil {
+		t.Errorf("SetParent returned error: %v", err)
+	}
+}
+
+func TestDependencyGraph(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/projects/1/tasks", func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.URL.Query().Get("opt_fields"), "name,completed,start_on,due_on,due_at,dependencies"; got != want {
+			t.Errorf("opt_fields = %q, want %q", got, want)
+		}
+		fmt.Fprint(w, `{"data":[
+			{"gid":"D","due_on":"2024-05-12","dependencies":[{"gid":"B"},{"gid":"C"}]},
+			{"gid":"C","due_on":"2024-05-10","dependencies":[{"gid":"A"}]},
+			{"gid":"B","due_on":"2024-05-03","dependencies":[{"gid":"A"},{"gid":"X"}]},
+			{"gid":"A","due_on":"2024-05-01"},
+			{"gid":"E"}
+		]}`)
+	})
+
+	g, err := client.GetProjectDependencyGraph(context.Background(), "1")
+	if err != nil {
+		t.Fatalf("GetProjectDependencyGraph returned error: %v", err)
+	}
+	gids := func(tasks []Task) []GID {
+		var rets []GID
+		for _, t := range tasks {
+			rets = append(rets, t.GID)
+		}
+		return rets
+	}
+
+	order, err := g.TopologicalOrder()
+	if err != nil {
+		t.Fatalf("TopologicalOrder returned error: %v", err)
+	}
+	if got, want := gids(order), []GID{"A", "E", "C", "B", "D"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("TopologicalOrder returned %v, want %v", got, want)
+	}
+	path, err := g.CriticalPath()
+	if err != nil {
+		t.Fatalf("CriticalPath returned error: %v", err)
+	}
+	if got, want := gids(path), []GID{"A", "C", "D"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("CriticalPath returned %v, want %v", got, want)
+	}
+	if got, want := g.Dependents("A"), []GID{"C", "B"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Dependents returned %v, want %v", got, want)
+	}
+
+	// The task due last, L, is on a shorter chain than M1 and M2, and M2
+	// spans three days.
+	diamond := NewDependencyGraph([]Task{
+		{GID: "S", DueOn: "2024-05-01"},
+		{GID: "L", DueOn: "2024-05-20", Dependencies: []Task{{GID: "S"}}},
+		{GID: "M1", DueOn: "2024-05-05", Dependencies: []Task{{GID: "S"}}},
+		{GID: "M2", StartOn: "2024-05-06", DueOn: "2024-05-08", Dependencies: []Task{{GID: "M1"}}},
+		{GID: "N", DueOn: "2024-05-02", Dependencies: []Task{{GID: "S"}}},
+		{GID: "E", DueOn: "2024-05-21", Dependencies: []Task{{GID: "L"}, {GID: "M2"}, {GID: "N"}}},
+	})
+	path, err = diamond.CriticalPath()
+	if err != nil {
+		t.Fatalf("CriticalPath returned error: %v", err)
+	}
+	if got, want := gids(path), []GID{"S", "M1", "M2", "E"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("CriticalPath returned %v, want %v", got, want)
+	}
+
+	cyclic := NewDependencyGraph([]Task{
+		{GID: "A", Dependencies: []Task{{GID: "C"}}},
+		{GID: "B", Dependencies: []Task{{GID: "A"}}},
+		{GID: "C", Dependencies: []Task{{GID: "B"}}},
+		{GID: "D"},
+	})
+	_, err = cyclic.TopologicalOrder()
+	var cycleErr *CycleError
+	if !errors.As(err, &cycleErr) || !reflect.DeepEqual(cycleErr.Cycle, []GID{"A", "C", "B"}) {
+		t.Errorf("TopologicalOrder of a cycle returned %v", err)
+	}
+}
+
+func TestAddDependencies(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/tasks/1/addDependencies", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		b, _ := ioutil.ReadAll(r.Body)
+		if want := `{"data":{"dependencies":["2","3"]}}`; string(b) != want {
+			t.Errorf("Request body: %s, want %s", b, want)
+		}
+		fmt.Fprint(w, `{"data":{}}`)
+	})
+
+	if err := client.AddDependencies(context.Background(), "1", []GID{"2", "3"}, nil); err != nil {
+		t.Errorf("AddDependencies returned error: %v", err)
+	}
+}
+
+func TestStories(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/tasks/1/stories", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		b, _ := ioutil.ReadAll(r.Body)
+		if want := `{"data":{"html_text":"\u003cbody\u003eShipped\u003c/body\u003e"}}`; string(b) != want {
+			t.Errorf("Request body: %s, want %s", b, want)
+		}
+		fmt.Fprint(w, `{"data":{"gid":"5","resource_subtype":"comment_added","html_text":"<body>Shipped</body>","text":"Shipped"}}`)
+	})
+	var deleted bool
+	mux.HandleFunc("/stories/5", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "PUT":
+			b, _ := ioutil.ReadAll(r.Body)
+			if want := `{"data":{"is_pinned":true}}`; string(b) != want {
+				t.Errorf("Request body: %s, want %s", b, want)
+			}
+			fmt.Fprint(w, `{"data":{"gid":"5","is_pinned":true}}`)
+		case "DELETE":
+			deleted = true
+			fmt.Fprint(w, `{"data":{}}`)
+		default:
+			t.Errorf("Request method: %v", r.Method)
+		}
+	})
+
+	ctx := context.Background()
+	story, err := client.CreateStory(ctx, "1", map[string]interface{}{"html_text": "<body>Shipped</body>"}, nil)
+	if err != nil {
+		t.Fatalf("CreateStory returned error: %v", err)
+	}
+	want := Story{GID: "5", ResourceSubtype: "comment_added", HTMLText: "<body>Shipped</body>", Text: "Shipped"}
+	if !reflect.DeepEqual(story, want) {
+		t.Errorf("CreateStory returned %+v, want %+v", story, want)
+	}
+	if story, err := client.PinStory(ctx, "5", true); err != nil || !story.IsPinned {
+		t.Errorf("PinStory returned %+v, %v", story, err)
+	}
+	if err := client.DeleteStory(ctx, "5"); err != nil || !deleted {
+		t.Errorf("DeleteStory returned %v", err)
+	}
+
+	var changed Story
+	json.Unmarshal([]byte(`{"resource_subtype":"enum_custom_field_changed","custom_field":{"gid":"7","name":"Priority"},
+		"old_enum_value":{"gid":"71","name":"Low"},"new_enum_value":{"gid":"72","name":"High"}}`), &changed)
+	if changed.CustomField.Name != "Priority" || changed.OldEnumValue.Name != "Low" || changed.NewEnumValue.Name != "High" {
+		t.Errorf("Decoded story %+v", changed)
+	}
+}
+
+func TestUploadAttachment(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var called int
+	mux.HandleFunc("/attachments", func(w http.ResponseWriter, r *http.Request) {
+		called++
+		testMethod(t, r, "POST")
+		if r.ContentLength != -1 {
+			t.Errorf("ContentLength = %d, want a streamed body", r.ContentLength)
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatalf("ParseMultipartForm: %v", err)
+		}
+		if got := r.FormValue("parent"); got != "1" {
+			t.Errorf("parent = %q, want %q", got, "1")
+		}
+		f, h, err := r.FormFile("file")
+		if err != nil {
+			t.Fatalf("FormFile: %v", err)
+		}
+		b, _ := ioutil.ReadAll(f)
+		if h.Filename != "notes.txt" || h.Header.Get("Content-Type") != "text/plain; charset=utf-8" || string(b) != "release notes" {
+			t.Errorf("Uploaded %q (%s): %q", h.Filename, h.Header.Get("Content-Type"), b)
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	// An io.Reader of unknown length, sent once even with a retry policy.
+	client.Retry = &RetryPolicy{MaxAttempts: 3, RetryNonIdempotent: true}
+	r := ioutil.NopCloser(strings.NewReader("release notes"))
+	_, err := client.UploadAttachment(context.Background(), "1", "notes.txt", r, nil)
+	if !IsServerError(err) {
+		t.Errorf("UploadAttachment returned %v, want a server error", err)
+	}
+	testCalled(t, called, 1)
+}
+
+func TestDownloadAttachment(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/attachments/5", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("opt_fields"); got != "download_url" {
+			t.Errorf("opt_fields = %q", got)
+		}
+		fmt.Fprintf(w, `{"data":{"gid":"5","download_url":"%s/files/5?sig=abc"}}`, server.URL)
+	})
+	mux.HandleFunc("/files/5", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("sig") != "abc" {
+			t.Errorf("Download URL query: %v", r.URL.RawQuery)
+		}
+		fmt.Fprint(w, "file content")
+	})
+
+	rc, err := client.DownloadAttachment(context.Background(), "5")
+	if err != nil {
+		t.Fatalf("DownloadAttachment returned error: %v", err)
+	}
+	defer rc.Close()
+	if b, _ := ioutil.ReadAll(rc); string(b) != "file content" {
+		t.Errorf("DownloadAttachment returned %q", b)
+	}
+}
+
+func TestListPortfolioProjects(t *testing.T) {
+	setup()
+	defer teardown()
+
+	items := map[string]string{
+		"1": `[{"gid":"10","name":"Web","resource_type":"project"},{"gid":"2","resource_type":"portfolio"},{"gid":"3","resource_type":"portfolio"}]`,
+		"2": `[{"gid":"11","name":"iOS","resource_type":"project"},{"gid":"3","resource_type":"portfolio"}]`,
+		"3": `[{"gid":"10","name":"Web","resource_type":"project"},{"gid":"12","name":"API","resource_type":"project"},{"gid":"1","resource_type":"portfolio"}]`,
+	}
+	var called int
+	for gid, data := range items {
+		data := data
+		mux.HandleFunc("/portfolios/"+gid+"/items", func(w http.ResponseWriter, r *http.Request) {
+			called++
+			fmt.Fprintf(w, `{"data":%s}`, data)
+		})
+	}
+
+	projects, err := client.ListPortfolioProjects(context.Background(), "1")
+	if err != nil {
+		t.Fatalf("ListPortfolioProjects returned error: %v", err)
+	}
+	want := []Project{{GID: "10", Name: "Web"}, {GID: "11", Name: "iOS"}, {GID: "12", Name: "API"}}
+	if !reflect.DeepEqual(projects, want) {
+		t.Errorf("ListPortfolioProjects returned %+v, want %+v", projects, want)
+	}
+	testCalled(t, called, 3)
+}
+
+func TestPortfolioMembers(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/portfolios/1/addMembers", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		b, _ := ioutil.ReadAll(r.Body)
+		if want := `{"data":{"members":"5,6"}}`; string(b) != want {
+			t.Errorf("Request body: %s, want %s", b, want)
+		}
+		fmt.Fprint(w, `{"data":{"gid":"1","members":[{"gid":"5"},{"gid":"6"}]}}`)
+	})
+
+	portfolio, err := client.AddPortfolioMembers(context.Background(), "1", []GID{"5", "6"}, nil)
+	if err != nil || len(portfolio.Members) != 2 {
+		t.Errorf("AddPortfolioMembers returned %+v, %v", portfolio, err)
+	}
+}
+
+func TestGoals(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/goals/1/setMetricCurrentValue", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		b, _ := ioutil.ReadAll(r.Body)
+		if want := `{"data":{"current_number_value":42.5}}`; string(b) != want {
+			t.Errorf("Request body: %s, want %s", b, want)
+		}
+		fmt.Fprint(w, `{"data":{"gid":"1","metric":{"unit":"percentage","target_number_value":100,"current_number_value":42.5}}}`)
+	})
+	mux.HandleFunc("/goal_relationships", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		q := r.URL.Query()
+		if q.Get("supported_goal") != "1" || q.Get("opt_fields") != "resource_subtype,supporting_resource.name" {
+			t.Errorf("Request query: %v", q)
+		}
+		switch q.Get("offset") {
+		case "":
+			fmt.Fprint(w, `{"data":[{"resource_subtype":"subgoal","supporting_resource":{"gid":"2","name":"Hire"}}],"next_page":{"offset":"o1"}}`)
+		case "o1":
+			fmt.Fprint(w, `{"data":[{"resource_subtype":"supporting_work","supporting_resource":{"gid":"3"}},
+				{"resource_subtype":"subgoal","supporting_resource":{"gid":"4","name":"Ship"}}]}`)
+		}
+	})
+
+	ctx := context.Background()
+	goal, err := client.SetGoalMetricCurrentValue(ctx, "1", 42.5, nil)
+	if err != nil || goal.Metric == nil || goal.Metric.CurrentNumberValue != 42.5 || goal.Metric.Unit != "percentage" {
+		t.Errorf("SetGoalMetricCurrentValue returned %+v, %v", goal, err)
+	}
+
+	subgoals, err := client.ListSubgoals(ctx, "1")
+	if err != nil {
+		t.Fatalf("ListSubgoals returned error: %v", err)
+	}
+	if want := []Resource{{GID: "2", Name: "Hire"}, {GID: "4", Name: "Ship"}}; !reflect.DeepEqual(subgoals, want) {
+		t.Errorf("ListSubgoals returned %+v, want %+v", subgoals, want)
+	}
+}
+
+func TestListStatusUpdates(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/status_updates", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("parent"); got != "1" {
+			t.Errorf("parent = %q, want %q", got, "1")
+		}
+		fmt.Fprint(w, `{"data":[{"gid":"9","status_type":"at_risk","title":"Week 12"}]}`)
+	})
+
+	updates, err := client.ListStatusUpdates(context.Background(), &Filter{Parent: "1"})
+	if err != nil {
+		t.Fatalf("ListStatusUpdates returned error: %v", err)
+	}
+	if want := []StatusUpdate{{GID: "9", StatusType: "at_risk", Title: "Week 12"}}; !reflect.DeepEqual(updates, want) {
+		t.Errorf("ListStatusUpdates returned %+v, want %+v", updates, want)
+	}
+}
+
+func TestTeams(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/organizations/1/teams", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		switch r.URL.Query().Get("offset") {
+		case "":
+			fmt.Fprint(w, `{"data":[{"gid":"10","name":"Design"}],"next_page":{"offset":"o1"}}`)
+		case "o1":
+			fmt.Fprint(w, `{"data":[{"gid":"11","name":"Engineering"}]}`)
+		}
+	})
+	mux.HandleFunc("/teams/11/addUser", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		b, _ := ioutil.ReadAll(r.Body)
+		if want := `{"data":{"user":"5"}}`; string(b) != want {
+			t.Errorf("Request body: %s, want %s", b, want)
+		}
+		fmt.Fprint(w, `{"data":{"gid":"20","user":{"gid":"5"},"team":{"gid":"11"}}}`)
+	})
+	mux.HandleFunc("/teams/11/projects", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		if got := r.URL.Query().Get("archived"); got != "true" {
+			t.Errorf("archived = %q, want %q", got, "true")
+		}
+		fmt.Fprint(w, `{"data":[{"gid":"30","name":"Old roadmap","archived":true}]}`)
+	})
+
+	ctx := context.Background()
+	teams, err := client.ListTeams(ctx, "1", nil)
+	if err != nil {
+		t.Fatalf("ListTeams returned error: %v", err)
+	}
+	if want := []Team{{GID: "10", Name: "Design"}, {GID: "11", Name: "Engineering"}}; !reflect.DeepEqual(teams, want) {
+		t.Errorf("ListTeams returned %+v, want %+v", teams, want)
+	}
+
+	membership, err := client.AddUserToTeam(ctx, "11", "5", nil)
+	if err != nil || membership.User.GID != "5" || membership.Team.GID != "11" {
+		t.Errorf("AddUserToTeam returned %+v, %v", membership, err)
+	}
+
+	projects, err := client.ListTeamProjects(ctx, "11", &Filter{Archived: true})
+	if err != nil {
+		t.Fatalf("ListTeamProjects returned error: %v", err)
+	}
+	if want := []Project{{GID: "30", Name: "Old roadmap", Archived: true}}; !reflect.DeepEqual(projects, want) {
+		t.Errorf("ListTeamProjects returned %+v, want %+v", projects, want)
+	}
+}
+
+func TestUpdateProject(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/projects/1", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "PUT")
+		b, _ := ioutil.ReadAll(r.Body)
+		if want := `{"data":{"public":false,"due_on":"2024-06-30","owner":"5"}}`; string(b) != want {
+			t.Errorf("Request body: %s, want %s", b, want)
+		}
+		fmt.Fprint(w, `{"data":{"gid":"1","due_on":"2024-06-30","owner":{"gid":"5"}}}`)
+	})
+
+	public, dueOn, owner := false, "2024-06-30", GID("5")
+	project, err := client.UpdateProject(context.Background(), "1", ProjectUpdate{Public: &public, DueOn: &dueOn, Owner: &owner}, nil)
+	if err != nil {
+		t.Fatalf("UpdateProject returned error: %v", err)
+	}
+	if want := (Project{GID: "1", DueOn: "2024-06-30", Owner: &User{GID: "5"}}); !reflect.DeepEqual(project, want) {
+		t.Errorf("UpdateProject returned %+v, want %+v", project, want)
+	}
+}
+
+func TestDuplicateProject(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/projects/1/duplicate", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "POST")
+		b, _ := ioutil.ReadAll(r.Body)
+		if want := `{"data":{"name":"Q3 launch","include":"members,task_notes","schedule_dates":{"should_skip_weekends":true,"due_on":"2024-09-30"}}}`; string(b) != want {
+			t.Errorf("Request body: %s, want %s", b, want)
+		}
+		fmt.Fprint(w, `{"data":{"gid":"7","resource_subtype":"duplicate_project","status":"not_started"}}`)
+	})
+	var mu sync.Mutex
+	polls := 0
+	mux.HandleFunc("/jobs/7", func(w http.ResponseWriter, r *http.Request) {
+		testMethod(t, r, "GET")
+		mu.Lock()
+		polls++
+		n := polls
+		mu.Unlock()
+		if n < 3 {
+			fmt.Fprint(w, `{"data":{"gid":"7","status":"in_progress"}}`)
+			return
+		}
+		fmt.Fprint(w, `{"data":{"gid":"7","status":"succeeded","new_project":{"gid":"8","name":"Q3 launch"}}}`)
+	})
+
+	ctx := context.Background()
+	job, err := client.DuplicateProject(ctx, "1", ProjectDuplicate{
+		Name:          "Q3 launch",
+		Include:       "members,task_notes",
+		ScheduleDates: &ProjectScheduleDates{ShouldSkipWeekends: true, DueOn: "2024-09-30"},
+	}, nil)
+	if err != nil {
+		t.Fatalf("DuplicateProject returned error: %v", err)
+	}
+	project, err := client.WaitForJob(ctx, job.GID, &JobWaitOptions{MinBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("WaitForJob returned error: %v", err)
+	}
+	if want := (Project{GID: "8", Name: "Q3 launch"}); !reflect.DeepEqual(project, want) {
+		t.Errorf("WaitForJob returned %+v, want %+v", project, want)
+	}
+	if polls != 3 {
+		t.Errorf("WaitForJob polled %d times, want 3", polls)
+	}
+}
+
+func TestWaitForJobFailed(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/jobs/7", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data":{"gid":"7","status":"failed"}}`)
+	})
+
+	_, err := client.WaitForJob(context.Background(), "7", nil)
+	if !errors.Is(err, ErrJobFailed) {
+		t.Errorf("WaitForJob returned %v, want ErrJobFailed", err)
+	}
+}
+
+func TestWaitForJobUnexpectedStatus(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/jobs/7", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data":{"gid":"7"}}`)
+	})
+	mux.HandleFunc("/jobs/8", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data":{"gid":"8","status":"in_progress"}}`)
+	})
+
+	_, err := client.WaitForJob(context.Background(), "7", nil)
+	if err == nil || errors.Is(err, ErrJobFailed) {
+		t.Errorf("WaitForJob of a job without status returned %v, want an unexpected status error", err)
+	}
+
+	_, err = client.WaitForJob(context.Background(), "8", &JobWaitOptions{MinBackoff: time.Millisecond, MaxWait: 20 * time.Millisecond})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("WaitForJob of a job in progress returned %v, want context.DeadlineExceeded", err)
+	}
+}
+
 func testMethod(t *testing.T, r *http.Request, want string) {
 	if got := r.Method; got != want {
 		t.Errorf("Request method: %v, want %v", got, want)
@@ -0,0 +1,271 @@
+package asanatest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sync"
+
+	"github.com/tambet/go-asana/asana"
+)
+
+// Redacted replaces scrubbed header and field values in cassettes.
+const Redacted = "REDACTED"
+
+type (
+	// Cassette holds recorded request/response pairs.
+	Cassette struct {
+		// Scrubbed lists the JSON fields whose values were redacted, so that
+		// request bodies are scrubbed the same way before being matched.
+		Scrubbed     []string      `json:"scrubbed,omitempty"`
+		Interactions []Interaction `json:"interactions"`
+	}
+
+	// Interaction is a recorded request and its response.
+	Interaction struct {
+		Method         string      `json:"method"`
+		URL            string      `json:"url"`
+		RequestHeader  http.Header `json:"request_header,omitempty"`
+		RequestBody    string      `json:"request_body,omitempty"`
+		Status         int         `json:"status"`
+		ResponseHeader http.Header `json:"response_header,omitempty"`
+		ResponseBody   string      `json:"response_body,omitempty"`
+	}
+
+	// Recorder is an asana.Doer recording the requests it passes on to Doer.
+	//
+	// The Authorization header is always redacted from the cassette, the
+	// headers in ScrubHeaders and the JSON fields in ScrubFields too.
+	// Responses returned to the caller are not scrubbed.
+	Recorder struct {
+		Doer         asana.Doer
+		ScrubHeaders []string
+		ScrubFields  []string
+
+		mu       sync.Mutex
+		cassette Cassette
+	}
+
+	// Replayer is an asana.Doer serving the responses of a cassette. A request
+	// is matched to the first unused interaction with the same method, path,
+	// query and body; it fails when there is none.
+	Replayer struct {
+		mu       sync.Mutex
+		cassette *Cassette
+		used     []bool
+	}
+)
+
+// NewRecorder returns a recorder passing requests on to doer, or to
+// http.DefaultClient if doer is nil.
+func NewRecorder(doer asana.Doer) *Recorder {
+	if doer == nil {
+		doer = http.DefaultClient
+	}
+	return &Recorder{Doer: doer}
+}
+
+func (r *Recorder) Do(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		if reqBody, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = ioutil.NopCloser(bytes.NewReader(reqBody))
+	}
+	reqHeader := r.scrubHeader(req.Header)
+
+	resp, err := r.Doer.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Method:         req.Method,
+		URL:            req.URL.RequestURI(),
+		RequestHeader:  reqHeader,
+		RequestBody:    string(scrubBody(reqBody, r.ScrubFields)),
+		Status:         resp.StatusCode,
+		ResponseHeader: r.scrubHeader(resp.Header),
+		ResponseBody:   string(scrubBody(respBody, r.ScrubFields)),
+	})
+	return resp, nil
+}
+
+// Cassette returns a copy of the interactions recorded so far.
+func (r *Recorder) Cassette() *Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &Cassette{
+		Scrubbed:     append([]string(nil), r.ScrubFields...),
+		Interactions: append([]Interaction(nil), r.cassette.Interactions...),
+	}
+}
+
+// Save writes the interactions recorded so far to a cassette file.
+func (r *Recorder) Save(path string) error {
+	return r.Cassette().Save(path)
+}
+
+func (r *Recorder) scrubHeader(h http.Header) http.Header {
+	h = h.Clone()
+	for _, name := range append([]string{"Authorization"}, r.ScrubHeaders...) {
+		if h.Get(name) != "" {
+			h.Set(name, Redacted)
+		}
+	}
+	return h
+}
+
+// LoadCassette reads a cassette file written by Cassette.Save.
+func LoadCassette(path string) (*Cassette, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c := new(Cassette)
+	if err := json.Unmarshal(b, c); err != nil {
+		return nil, fmt.Errorf("asanatest: cassette %s: %v", path, err)
+	}
+	return c, nil
+}
+
+// Save writes the cassette to a file.
+func (c *Cassette) Save(path string) error {
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(b, '\n'), 0644)
+}
+
+// NewReplayer returns a replayer serving the interactions of c.
+func NewReplayer(c *Cassette) *Replayer {
+	return &Replayer{cassette: c, used: make([]bool, len(c.Interactions))}
+}
+
+func (r *Replayer) Do(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+	}
+	body = scrubBody(body, r.cassette.Scrubbed)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, in := range r.cassette.Interactions {
+		if r.used[i] || !in.matches(req, body) {
+			continue
+		}
+		r.used[i] = true
+		header := in.ResponseHeader.Clone()
+		if header == nil {
+			header = http.Header{}
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", in.Status, http.StatusText(in.Status)),
+			StatusCode:    in.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          ioutil.NopCloser(bytes.NewReader([]byte(in.ResponseBody))),
+			ContentLength: int64(len(in.ResponseBody)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("asanatest: no recorded interaction for %s %s", req.Method, req.URL.RequestURI())
+}
+
+// Unused returns the interactions that were not replayed yet.
+func (r *Replayer) Unused() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var rets []Interaction
+	for i, in := range r.cassette.Interactions {
+		if !r.used[i] {
+			rets = append(rets, in)
+		}
+	}
+	return rets
+}
+
+func (in Interaction) matches(req *http.Request, body []byte) bool {
+	u, err := url.Parse(in.URL)
+	if err != nil || in.Method != req.Method || u.Path != req.URL.Path {
+		return false
+	}
+	if !reflect.DeepEqual(u.Query(), req.URL.Query()) {
+		return false
+	}
+	return sameBody([]byte(in.RequestBody), body)
+}
+
+// sameBody compares JSON bodies regardless of key order and other bodies byte by byte.
+func sameBody(a, b []byte) bool {
+	if len(bytes.TrimSpace(a)) == 0 || len(bytes.TrimSpace(b)) == 0 {
+		return len(bytes.TrimSpace(a)) == len(bytes.TrimSpace(b))
+	}
+	var va, vb interface{}
+	if json.Unmarshal(a, &va) == nil && json.Unmarshal(b, &vb) == nil {
+		return reflect.DeepEqual(va, vb)
+	}
+	return bytes.Equal(a, b)
+}
+
+// scrubBody redacts the values of fields anywhere in a JSON body.
+// Other bodies are returned unchanged.
+func scrubBody(body []byte, fields []string) []byte {
+	if len(fields) == 0 || len(body) == 0 {
+		return body
+	}
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return body
+	}
+	scrub := map[string]bool{}
+	for _, f := range fields {
+		scrub[f] = true
+	}
+	b, err := json.Marshal(scrubValue(v, scrub))
+	if err != nil {
+		return body
+	}
+	return b
+}
+
+func scrubValue(v interface{}, fields map[string]bool) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, vv := range v {
+			if fields[k] {
+				v[k] = Redacted
+			} else {
+				v[k] = scrubValue(vv, fields)
+			}
+		}
+	case []interface{}:
+		for i, vv := range v {
+			v[i] = scrubValue(vv, fields)
+		}
+	}
+	return v
+}
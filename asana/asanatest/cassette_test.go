@@ -0,0 +1,70 @@
+package asanatest
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tambet/go-asana/asana"
+)
+
+func TestCassette(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	ctx := context.Background()
+
+	ws := srv.AddWorkspace(asana.Workspace{Name: "Acme"})
+	srv.AddUser(asana.User{Name: "Alice", Email: "alice@example.com"})
+
+	rec := NewRecorder(srv.Server.Client())
+	rec.ScrubFields = []string{"email"}
+	live := asana.NewClient(asana.DoerFunc(func(req *http.Request) (*http.Response, error) {
+		req.Header.Set("Authorization", "Bearer secret")
+		return rec.Do(req)
+	}))
+	live.BaseURL = srv.Client().BaseURL
+
+	user, err := live.GetAuthenticatedUser(ctx, nil)
+	if err != nil || user.Email != "alice@example.com" {
+		t.Fatalf("GetAuthenticatedUser returned %+v, %v", user, err)
+	}
+	if _, err := live.CreateTask(ctx, map[string]interface{}{"name": "Ship it", "workspace": ws.GID}, nil); err != nil {
+		t.Fatalf("CreateTask returned error: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	if err := rec.Save(path); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+	cassette, err := LoadCassette(path)
+	if err != nil {
+		t.Fatalf("LoadCassette returned error: %v", err)
+	}
+	if got := cassette.Interactions[0].RequestHeader.Get("Authorization"); got != Redacted {
+		t.Errorf("Authorization header recorded as %q", got)
+	}
+	if strings.Contains(cassette.Interactions[0].ResponseBody, "alice@example.com") {
+		t.Errorf("Email recorded in %s", cassette.Interactions[0].ResponseBody)
+	}
+
+	srv.Close()
+	replayer := NewReplayer(cassette)
+	offline := asana.NewClient(replayer)
+	offline.BaseURL = live.BaseURL
+
+	if _, err := offline.GetAuthenticatedUser(ctx, nil); err != nil {
+		t.Errorf("GetAuthenticatedUser replay returned error: %v", err)
+	}
+	if _, err := offline.CreateTask(ctx, map[string]interface{}{"name": "Other", "workspace": ws.GID}, nil); err == nil {
+		t.Errorf("CreateTask replay with another body returned no error")
+	}
+	task, err := offline.CreateTask(ctx, map[string]interface{}{"workspace": ws.GID, "name": "Ship it"}, nil)
+	if err != nil || task.Name != "Ship it" {
+		t.Errorf("CreateTask replay returned %+v, %v", task, err)
+	}
+	if unused := replayer.Unused(); len(unused) != 0 {
+		t.Errorf("Unused returned %+v", unused)
+	}
+}
@@ -0,0 +1,663 @@
+package asanatest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/tambet/go-asana/asana"
+)
+
+func (s *Server) listWorkspaces() []interface{} {
+	var rets []interface{}
+	for _, w := range s.workspaces.list() {
+		rets = append(rets, w)
+	}
+	return rets
+}
+
+func (s *Server) getWorkspace(gid asana.GID) (interface{}, error) {
+	w, ok := s.workspaces.get(gid)
+	if !ok {
+		return nil, notFound("workspace", gid)
+	}
+	return w, nil
+}
+
+func (s *Server) listUsers(workspaceGID asana.GID) []interface{} {
+	var rets []interface{}
+	for _, u := range s.users.list() {
+		if workspaceGID != "" && !inWorkspace(u.Workspaces, workspaceGID) {
+			continue
+		}
+		rets = append(rets, u)
+	}
+	return rets
+}
+
+func (s *Server) getUser(gid asana.GID) (interface{}, error) {
+	u, ok := s.users.get(gid)
+	if !ok {
+		return nil, notFound("user", gid)
+	}
+	return u, nil
+}
+
+func (s *Server) listProjects(workspaceGID asana.GID, archived string) []interface{} {
+	var rets []interface{}
+	for _, p := range s.projects.list() {
+		if workspaceGID != "" && p.workspace != workspaceGID {
+			continue
+		}
+		if archived != "" && (archived == "true") != p.Archived {
+			continue
+		}
+		rets = append(rets, &p.Project)
+	}
+	return rets
+}
+
+func (s *Server) getProject(gid asana.GID) (interface{}, error) {
+	p, ok := s.projects.get(gid)
+	if !ok {
+		return nil, notFound("project", gid)
+	}
+	return &p.Project, nil
+}
+
+func (s *Server) listProjectTasks(gid asana.GID) (interface{}, error) {
+	if _, ok := s.projects.get(gid); !ok {
+		return nil, notFound("project", gid)
+	}
+	rets := []interface{}{}
+	for _, t := range s.tasks.list() {
+		if hasProject(t.Projects, gid) {
+			rets = append(rets, s.renderTask(t))
+		}
+	}
+	return rets, nil
+}
+
+func (s *Server) listProjectSections(gid asana.GID) (interface{}, error) {
+	if _, ok := s.projects.get(gid); !ok {
+		return nil, notFound("project", gid)
+	}
+	rets := []interface{}{}
+	for _, sec := range s.sections.list() {
+		if sec.Project.GID == gid {
+			rets = append(rets, sec)
+		}
+	}
+	return rets, nil
+}
+
+func (s *Server) listTags(workspaceGID asana.GID) []interface{} {
+	var rets []interface{}
+	for _, t := range s.tags.list() {
+		if workspaceGID == "" || t.workspace == workspaceGID {
+			rets = append(rets, &t.Tag)
+		}
+	}
+	return rets
+}
+
+// listTasks lists the tasks of a project, or of an assignee in a workspace,
+// as the tasks endpoint requires one or the other.
+func (s *Server) listTasks(q url.Values) (interface{}, error) {
+	projectGID := asana.GID(q.Get("project"))
+	assignee := asana.GID(q.Get("assignee"))
+	workspaceGID := asana.GID(q.Get("workspace"))
+	if projectGID == "" && (assignee == "" || workspaceGID == "") {
+		return nil, errorf(http.StatusBadRequest, "Must specify exactly one of project, tag, section, user task list, or assignee + workspace")
+	}
+	if assignee == "me" {
+		assignee = s.me
+	}
+	rets := []interface{}{}
+	for _, t := range s.tasks.list() {
+		switch {
+		case projectGID != "" && !hasProject(t.Projects, projectGID):
+			continue
+		case assignee != "" && (t.Assignee == nil || t.Assignee.GID != assignee || t.workspace != workspaceGID):
+			continue
+		}
+		if since := q.Get("modified_since"); since != "" {
+			at, err := time.Parse(time.RFC3339, since)
+			if err != nil {
+				return nil, errorf(http.StatusBadRequest, "modified_since: Not a valid date-time")
+			}
+			if t.ModifiedAt.Before(at) {
+				continue
+			}
+		}
+		rets = append(rets, s.renderTask(t))
+	}
+	return rets, nil
+}
+
+// taskInput is the data of a task creation.
+type taskInput struct {
+	Name         string                    `json:"name"`
+	Notes        string                    `json:"notes"`
+	Completed    bool                      `json:"completed"`
+	DueOn        string                    `json:"due_on"`
+	DueAt        string                    `json:"due_at"`
+	Assignee     asana.GID                 `json:"assignee"`
+	Workspace    asana.GID                 `json:"workspace"`
+	Projects     []asana.GID               `json:"projects"`
+	Tags         []asana.GID               `json:"tags"`
+	Parent       asana.GID                 `json:"parent"`
+	External     *asana.External           `json:"external"`
+	CustomFields map[asana.GID]interface{} `json:"custom_fields"`
+}
+
+func (s *Server) createTask(r *http.Request) (interface{}, error) {
+	var in taskInput
+	if err := decodeData(r, &in); err != nil {
+		return nil, err
+	}
+	workspaceGID := in.Workspace
+	for _, gid := range in.Projects {
+		p, ok := s.projects.get(gid)
+		if !ok {
+			return nil, notFound("project", gid)
+		}
+		workspaceGID = p.workspace
+	}
+	if workspaceGID == "" {
+		return nil, errorf(http.StatusBadRequest, "workspace: Missing input")
+	}
+	if _, ok := s.workspaces.get(workspaceGID); !ok {
+		return nil, notFound("workspace", workspaceGID)
+	}
+	if in.External != nil && in.External.ID != "" {
+		if _, ok := s.findTask("external:" + in.External.ID); ok {
+			return nil, errorf(http.StatusBadRequest, "external: Duplicate external id %s", in.External.ID)
+		}
+	}
+
+	t := &task{workspace: workspaceGID}
+	t.GID = s.newGID()
+	t.Name, t.Notes, t.Completed, t.DueOn, t.DueAt = in.Name, in.Notes, in.Completed, in.DueOn, in.DueAt
+	t.CreatedAt = s.now()
+	t.ModifiedAt = t.CreatedAt
+	if in.Completed {
+		t.CompletedAt = t.CreatedAt
+	}
+	if in.Assignee != "" {
+		t.Assignee = &asana.User{GID: in.Assignee}
+	}
+	if in.Parent != "" {
+		t.ParentTask = &asana.Task{GID: in.Parent}
+	}
+	if in.External != nil {
+		t.External = *in.External
+	}
+	for _, gid := range in.Projects {
+		t.Projects = append(t.Projects, asana.Project{GID: gid})
+		t.Memberships = append(t.Memberships, asana.Membership{Project: asana.Project{GID: gid}})
+	}
+	for _, gid := range in.Tags {
+		t.Tags = append(t.Tags, asana.Tag{GID: gid})
+	}
+	for gid, v := range in.CustomFields {
+		t.CustomFields = append(t.CustomFields, customFieldValue(asana.CustomField{GID: gid}, v))
+	}
+	s.tasks.put(t.GID, t)
+	return s.renderTask(t), nil
+}
+
+func (s *Server) getTask(id string) (interface{}, error) {
+	t, ok := s.findTask(id)
+	if !ok {
+		return nil, notFound("task", asana.GID(id))
+	}
+	return s.renderTask(t), nil
+}
+
+func (s *Server) updateTask(r *http.Request, id string) (interface{}, error) {
+	t, ok := s.findTask(id)
+	if !ok {
+		return nil, notFound("task", asana.GID(id))
+	}
+	var in map[string]json.RawMessage
+	if err := decodeData(r, &in); err != nil {
+		return nil, err
+	}
+	up := t.Task
+	for key, raw := range in {
+		var err error
+		switch key {
+		case "name":
+			err = json.Unmarshal(raw, &up.Name)
+		case "notes":
+			err = json.Unmarshal(raw, &up.Notes)
+		case "due_on":
+			err = json.Unmarshal(raw, &up.DueOn)
+		case "due_at":
+			err = json.Unmarshal(raw, &up.DueAt)
+		case "hearted":
+			err = json.Unmarshal(raw, &up.Hearted)
+		case "completed":
+			err = json.Unmarshal(raw, &up.Completed)
+			if err == nil && up.Completed && !t.Completed {
+				up.CompletedAt = s.now()
+			}
+		case "completed_at":
+			var at *time.Time
+			if err = json.Unmarshal(raw, &at); err == nil && at != nil {
+				up.CompletedAt = *at
+			}
+		case "assignee":
+			var gid *asana.GID
+			if err = json.Unmarshal(raw, &gid); err == nil {
+				up.Assignee = nil
+				if gid != nil && *gid != "" {
+					up.Assignee = &asana.User{GID: *gid}
+				}
+			}
+		case "custom_fields":
+			var values map[asana.GID]interface{}
+			if err = json.Unmarshal(raw, &values); err == nil {
+				up.CustomFields = setCustomFields(up.CustomFields, values)
+			}
+		default:
+			return nil, errorf(http.StatusBadRequest, "%s: Cannot write this property", key)
+		}
+		if err != nil {
+			return nil, errorf(http.StatusBadRequest, "%s: Invalid input", key)
+		}
+	}
+	up.ModifiedAt = s.now()
+	t.Task = up
+	return s.renderTask(t), nil
+}
+
+func (s *Server) deleteTask(id string) (interface{}, error) {
+	t, ok := s.findTask(id)
+	if !ok {
+		return nil, notFound("task", asana.GID(id))
+	}
+	s.tasks.delete(t.GID)
+	delete(s.stories, t.GID)
+	return struct{}{}, nil
+}
+
+func (s *Server) listStories(id string) (interface{}, error) {
+	t, ok := s.findTask(id)
+	if !ok {
+		return nil, notFound("task", asana.GID(id))
+	}
+	rets := []interface{}{}
+	for i := range s.stories[t.GID] {
+		rets = append(rets, &s.stories[t.GID][i])
+	}
+	return rets, nil
+}
+
+// taskAction handles the addTag, removeTag, addProject and removeProject endpoints.
+func (s *Server) taskAction(r *http.Request, id, action string) (interface{}, error) {
+	t, ok := s.findTask(id)
+	if !ok {
+		return nil, notFound("task", asana.GID(id))
+	}
+	var in struct {
+		Tag          asana.GID `json:"tag"`
+		Project      asana.GID `json:"project"`
+		Section      asana.GID `json:"section"`
+		InsertBefore asana.GID `json:"insert_before"`
+		InsertAfter  asana.GID `json:"insert_after"`
+	}
+	if err := decodeData(r, &in); err != nil {
+		return nil, err
+	}
+
+	switch action {
+	case "addTag", "removeTag":
+		if _, ok := s.tags.get(in.Tag); !ok {
+			return nil, notFound("tag", in.Tag)
+		}
+		tags := t.Tags[:0:0]
+		for _, tg := range t.Tags {
+			if tg.GID != in.Tag {
+				tags = append(tags, tg)
+			}
+		}
+		if action == "addTag" {
+			tags = append(tags, asana.Tag{GID: in.Tag})
+		}
+		t.Tags = tags
+
+	case "addProject", "removeProject":
+		if _, ok := s.projects.get(in.Project); !ok {
+			return nil, notFound("project", in.Project)
+		}
+		if in.Section != "" {
+			if sec, ok := s.sections.get(in.Section); !ok || sec.Project.GID != in.Project {
+				return nil, errorf(http.StatusBadRequest, "section: Not a section of project %s", in.Project)
+			}
+		}
+		projects := t.Projects[:0:0]
+		for _, p := range t.Projects {
+			if p.GID != in.Project {
+				projects = append(projects, p)
+			}
+		}
+		memberships := t.Memberships[:0:0]
+		for _, m := range t.Memberships {
+			if m.Project.GID != in.Project {
+				memberships = append(memberships, m)
+			}
+		}
+		if action == "addProject" {
+			projects = append(projects, asana.Project{GID: in.Project})
+			memberships = append(memberships, asana.Membership{
+				Project: asana.Project{GID: in.Project},
+				Section: asana.Section{GID: in.Section},
+			})
+		}
+		t.Projects, t.Memberships = projects, memberships
+
+	default:
+		return nil, errorf(http.StatusNotFound, "POST %s: No matching route for request", r.URL.Path)
+	}
+	t.ModifiedAt = s.now()
+	return struct{}{}, nil
+}
+
+func (s *Server) createSection(r *http.Request) (interface{}, error) {
+	var in struct {
+		Name     string          `json:"name"`
+		Project  asana.GID       `json:"project"`
+		External *asana.External `json:"external"`
+	}
+	if err := decodeData(r, &in); err != nil {
+		return nil, err
+	}
+	if _, ok := s.projects.get(in.Project); !ok {
+		return nil, notFound("project", in.Project)
+	}
+	sec := &asana.Section{GID: s.newGID(), Name: in.Name, Project: asana.Project{GID: in.Project}, CreatedAt: s.now()}
+	if in.External != nil {
+		sec.External = *in.External
+	}
+	s.sections.put(sec.GID, sec)
+	return sec, nil
+}
+
+func (s *Server) getSection(id string) (interface{}, error) {
+	sec, ok := s.findSection(id)
+	if !ok {
+		return nil, notFound("section", asana.GID(id))
+	}
+	return sec, nil
+}
+
+func (s *Server) updateSection(r *http.Request, id string) (interface{}, error) {
+	sec, ok := s.findSection(id)
+	if !ok {
+		return nil, notFound("section", asana.GID(id))
+	}
+	var in asana.SectionUpdate
+	if err := decodeData(r, &in); err != nil {
+		return nil, err
+	}
+	if in.Name != nil {
+		sec.Name = *in.Name
+	}
+	return sec, nil
+}
+
+func (s *Server) deleteSection(id string) (interface{}, error) {
+	sec, ok := s.findSection(id)
+	if !ok {
+		return nil, notFound("section", asana.GID(id))
+	}
+	s.sections.delete(sec.GID)
+	return struct{}{}, nil
+}
+
+func (s *Server) listWebhooks(workspaceGID asana.GID) (interface{}, error) {
+	if workspaceGID == "" {
+		return nil, errorf(http.StatusBadRequest, "workspace: Missing input")
+	}
+	rets := []interface{}{}
+	for _, w := range s.webhooks.list() {
+		if s.resourceWorkspace(w.Resource.GID) == workspaceGID {
+			rets = append(rets, w)
+		}
+	}
+	return rets, nil
+}
+
+func (s *Server) createWebhook(r *http.Request) (interface{}, error) {
+	var in struct {
+		Resource asana.GID             `json:"resource"`
+		Target   string                `json:"target"`
+		Filters  []asana.WebhookFilter `json:"filters"`
+	}
+	if err := decodeData(r, &in); err != nil {
+		return nil, err
+	}
+	if in.Target == "" {
+		return nil, errorf(http.StatusBadRequest, "target: Missing input")
+	}
+	res, ok := s.resource(in.Resource)
+	if !ok {
+		return nil, notFound("resource", in.Resource)
+	}
+	if res.ResourceType == "workspace" && len(in.Filters) == 0 {
+		return nil, errorf(http.StatusBadRequest, "filters: Webhooks on a workspace require filters")
+	}
+	w := &asana.Webhook{
+		GID:       s.newGID(),
+		Resource:  res,
+		Target:    in.Target,
+		Active:    true,
+		Filters:   in.Filters,
+		CreatedAt: s.now(),
+	}
+	s.webhooks.put(w.GID, w)
+	return w, nil
+}
+
+func (s *Server) getWebhook(gid asana.GID) (interface{}, error) {
+	w, ok := s.webhooks.get(gid)
+	if !ok {
+		return nil, notFound("webhook", gid)
+	}
+	return w, nil
+}
+
+func (s *Server) deleteWebhook(gid asana.GID) (interface{}, error) {
+	if _, ok := s.webhooks.get(gid); !ok {
+		return nil, notFound("webhook", gid)
+	}
+	s.webhooks.delete(gid)
+	return struct{}{}, nil
+}
+
+// findTask finds a task by gid or by "external:<id>".
+func (s *Server) findTask(id string) (*task, bool) {
+	if ext := strings.TrimPrefix(id, "external:"); ext != id {
+		for _, t := range s.tasks.list() {
+			if t.External.ID == ext {
+				return t, true
+			}
+		}
+		return nil, false
+	}
+	return s.tasks.get(asana.GID(id))
+}
+
+// findSection finds a section by gid or by "external:<id>".
+func (s *Server) findSection(id string) (*asana.Section, bool) {
+	if ext := strings.TrimPrefix(id, "external:"); ext != id {
+		for _, sec := range s.sections.list() {
+			if sec.External.ID == ext {
+				return sec, true
+			}
+		}
+		return nil, false
+	}
+	return s.sections.get(asana.GID(id))
+}
+
+// resource returns the compact form of the workspace, project or task with gid.
+func (s *Server) resource(gid asana.GID) (asana.Resource, bool) {
+	if w, ok := s.workspaces.get(gid); ok {
+		return asana.Resource{GID: gid, Name: w.Name, ResourceType: "workspace"}, true
+	}
+	if p, ok := s.projects.get(gid); ok {
+		return asana.Resource{GID: gid, Name: p.Name, ResourceType: "project"}, true
+	}
+	if t, ok := s.tasks.get(gid); ok {
+		return asana.Resource{GID: gid, Name: t.Name, ResourceType: "task"}, true
+	}
+	return asana.Resource{}, false
+}
+
+func (s *Server) resourceWorkspace(gid asana.GID) asana.GID {
+	if _, ok := s.workspaces.get(gid); ok {
+		return gid
+	}
+	if p, ok := s.projects.get(gid); ok {
+		return p.workspace
+	}
+	if t, ok := s.tasks.get(gid); ok {
+		return t.workspace
+	}
+	return ""
+}
+
+// renderTask returns a copy of t with the names of the resources it refers to.
+func (s *Server) renderTask(t *task) *asana.Task {
+	ret := t.Task
+	if ret.Assignee != nil {
+		a := *ret.Assignee
+		if u, ok := s.users.get(a.GID); ok {
+			a.Name = u.Name
+		}
+		ret.Assignee = &a
+	}
+	ret.Projects = make([]asana.Project, len(t.Projects))
+	for i, p := range t.Projects {
+		ret.Projects[i] = s.compactProject(p.GID)
+	}
+	ret.Memberships = make([]asana.Membership, len(t.Memberships))
+	for i, m := range t.Memberships {
+		ret.Memberships[i] = asana.Membership{Project: s.compactProject(m.Project.GID)}
+		if sec, ok := s.sections.get(m.Section.GID); ok {
+			ret.Memberships[i].Section = asana.Section{GID: sec.GID, Name: sec.Name}
+		}
+	}
+	ret.Tags = make([]asana.Tag, len(t.Tags))
+	for i, tg := range t.Tags {
+		ret.Tags[i] = asana.Tag{GID: tg.GID}
+		if stored, ok := s.tags.get(tg.GID); ok {
+			ret.Tags[i].Name = stored.Name
+		}
+	}
+	return &ret
+}
+
+func (s *Server) compactProject(gid asana.GID) asana.Project {
+	if p, ok := s.projects.get(gid); ok {
+		return asana.Project{GID: gid, Name: p.Name}
+	}
+	return asana.Project{GID: gid}
+}
+
+// setCustomFields sets the values of the custom fields of a task, keyed by
+// the gid of the field.
+func setCustomFields(fields []asana.CustomField, values map[asana.GID]interface{}) []asana.CustomField {
+	fields = append([]asana.CustomField(nil), fields...)
+	for gid, v := range values {
+		found := false
+		for i, cf := range fields {
+			if cf.GID == gid {
+				fields[i] = customFieldValue(cf, v)
+				found = true
+			}
+		}
+		if !found {
+			fields = append(fields, customFieldValue(asana.CustomField{GID: gid}, v))
+		}
+	}
+	return fields
+}
+
+// customFieldValue sets v as the value of cf according to its type, which
+// is inferred from v when unknown. A nil v clears the value.
+func customFieldValue(cf asana.CustomField, v interface{}) asana.CustomField {
+	if cf.Type == "" {
+		switch v.(type) {
+		case string:
+			cf.Type = "text"
+			if len(cf.EnumOptions) > 0 {
+				cf.Type = "enum"
+			}
+		case float64:
+			cf.Type = "number"
+		case []interface{}:
+			cf.Type = "multi_enum"
+		case map[string]interface{}:
+			cf.Type = "date"
+		}
+	}
+	cf.TextValue, cf.NumberValue, cf.EnumValue, cf.MultiEnumValues, cf.DateValue, cf.PeopleValue = "", nil, nil, nil, nil, nil
+	switch cf.Type {
+	case "text":
+		cf.TextValue, _ = v.(string)
+	case "number":
+		if n, ok := v.(float64); ok {
+			cf.NumberValue = &n
+		}
+	case "enum":
+		if gid, ok := v.(string); ok {
+			o := enumOption(cf, asana.GID(gid))
+			cf.EnumValue = &o
+		}
+	case "multi_enum", "people":
+		gids, _ := v.([]interface{})
+		for _, gid := range gids {
+			gid, _ := gid.(string)
+			if cf.Type == "people" {
+				cf.PeopleValue = append(cf.PeopleValue, asana.User{GID: asana.GID(gid)})
+			} else {
+				cf.MultiEnumValues = append(cf.MultiEnumValues, enumOption(cf, asana.GID(gid)))
+			}
+		}
+	case "date":
+		if m, ok := v.(map[string]interface{}); ok {
+			date, _ := m["date"].(string)
+			dateTime, _ := m["date_time"].(string)
+			cf.DateValue = &asana.CFDateValue{Date: date, DateTime: dateTime}
+		}
+	}
+	return cf
+}
+
+func enumOption(cf asana.CustomField, gid asana.GID) asana.CFEnumOptions {
+	for _, o := range cf.EnumOptions {
+		if o.GID == gid {
+			return o
+		}
+	}
+	return asana.CFEnumOptions{GID: gid}
+}
+
+func inWorkspace(workspaces []asana.Workspace, gid asana.GID) bool {
+	for _, w := range workspaces {
+		if w.GID == gid {
+			return true
+		}
+	}
+	return false
+}
+
+func notFound(kind string, gid asana.GID) error {
+	return errorf(http.StatusNotFound, "%s: Unknown object: %s", kind, gid)
+}
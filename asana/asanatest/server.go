@@ -0,0 +1,502 @@
+// Package asanatest provides an in-memory fake of the Asana API for tests.
+//
+// A Server keeps workspaces, users, projects, sections, tasks, tags, stories
+// and webhooks in memory and serves them on the paths used by asana.Client,
+// including external ids, opt_fields, offset/limit paging and error envelopes:
+//
+//	srv := asanatest.NewServer()
+//	defer srv.Close()
+//	ws := srv.AddWorkspace(asana.Workspace{Name: "Acme"})
+//	client := srv.Client()
+package asanatest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tambet/go-asana/asana"
+)
+
+const (
+	defaultLimit = 20
+	maxLimit     = 100
+)
+
+// Server is a stateful fake of the Asana API. It is safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	lastGID  int64
+	me       asana.GID
+	failures []failure
+
+	workspaces table[asana.Workspace]
+	users      table[asana.User]
+	projects   table[project]
+	sections   table[asana.Section]
+	tasks      table[task]
+	tags       table[tag]
+	webhooks   table[asana.Webhook]
+	stories    map[asana.GID][]asana.Story
+}
+
+type (
+	project struct {
+		asana.Project
+		workspace asana.GID
+	}
+
+	task struct {
+		asana.Task
+		workspace asana.GID
+	}
+
+	tag struct {
+		asana.Tag
+		workspace asana.GID
+	}
+
+	failure struct {
+		method, path string
+		code         int
+		message      string
+	}
+
+	// apiError is rendered as an Asana error envelope.
+	apiError struct {
+		code    int
+		message string
+	}
+)
+
+func (e *apiError) Error() string {
+	return e.message
+}
+
+func errorf(code int, format string, args ...interface{}) error {
+	return &apiError{code: code, message: fmt.Sprintf(format, args...)}
+}
+
+// NewServer starts a fake Asana API server. Close it when done.
+func NewServer() *Server {
+	s := &Server{lastGID: 1200000000000000, stories: map[asana.GID][]asana.Story{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Client returns a client talking to the server.
+func (s *Server) Client(opts ...asana.ClientOption) *asana.Client {
+	c := asana.NewClient(s.Server.Client(), opts...)
+	c.BaseURL, _ = url.Parse(s.URL + "/")
+	return c
+}
+
+// FailNext makes the next request with method on path fail with code and
+// message, e.g. FailNext("GET", "/tasks/1", 503, "Service Unavailable").
+func (s *Server) FailNext(method, path string, code int, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, failure{method: method, path: path, code: code, message: message})
+}
+
+func (s *Server) newGID() asana.GID {
+	s.lastGID++
+	return asana.IntGID(s.lastGID)
+}
+
+func (s *Server) now() time.Time {
+	return time.Now().UTC().Truncate(time.Millisecond)
+}
+
+// AddWorkspace stores w with a new gid and returns it.
+func (s *Server) AddWorkspace(w asana.Workspace) asana.Workspace {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w.GID = s.newGID()
+	s.workspaces.put(w.GID, &w)
+	return w
+}
+
+// AddUser stores u with a new gid and returns it. The first user added is
+// the authenticated user, see SetMe.
+func (s *Server) AddUser(u asana.User) asana.User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u.GID = s.newGID()
+	s.users.put(u.GID, &u)
+	if s.me == "" {
+		s.me = u.GID
+	}
+	return u
+}
+
+// SetMe sets the authenticated user returned by users/me.
+func (s *Server) SetMe(userGID asana.GID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.me = userGID
+}
+
+// AddProject stores p in a workspace with a new gid and returns it.
+func (s *Server) AddProject(workspaceGID asana.GID, p asana.Project) asana.Project {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p.GID = s.newGID()
+	s.projects.put(p.GID, &project{Project: p, workspace: workspaceGID})
+	return p
+}
+
+// AddSection stores a section of a project with a new gid and returns it.
+func (s *Server) AddSection(projectGID asana.GID, sec asana.Section) asana.Section {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sec.GID = s.newGID()
+	sec.Project = asana.Project{GID: projectGID}
+	if sec.CreatedAt.IsZero() {
+		sec.CreatedAt = s.now()
+	}
+	s.sections.put(sec.GID, &sec)
+	return sec
+}
+
+// AddTask stores t in a workspace with a new gid and returns it.
+// The task belongs to the projects listed in t.Projects and t.Memberships.
+func (s *Server) AddTask(workspaceGID asana.GID, t asana.Task) asana.Task {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t.GID = s.newGID()
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = s.now()
+	}
+	if t.ModifiedAt.IsZero() {
+		t.ModifiedAt = t.CreatedAt
+	}
+	for _, m := range t.Memberships {
+		if !hasProject(t.Projects, m.Project.GID) {
+			t.Projects = append(t.Projects, asana.Project{GID: m.Project.GID})
+		}
+	}
+	s.tasks.put(t.GID, &task{Task: t, workspace: workspaceGID})
+	return t
+}
+
+// AddTag stores t in a workspace with a new gid and returns it.
+func (s *Server) AddTag(workspaceGID asana.GID, t asana.Tag) asana.Tag {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t.GID = s.newGID()
+	s.tags.put(t.GID, &tag{Tag: t, workspace: workspaceGID})
+	return t
+}
+
+// AddStory stores a story of a task with a new gid and returns it.
+func (s *Server) AddStory(taskGID asana.GID, st asana.Story) asana.Story {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st.GID = s.newGID()
+	if st.CreatedAt.IsZero() {
+		st.CreatedAt = s.now()
+	}
+	s.stories[taskGID] = append(s.stories[taskGID], st)
+	return st
+}
+
+// Task returns the stored task.
+func (s *Server) Task(gid asana.GID) (asana.Task, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.tasks.get(gid)
+	if !ok {
+		return asana.Task{}, false
+	}
+	return t.Task, true
+}
+
+// Webhooks returns the stored webhooks.
+func (s *Server) Webhooks() []asana.Webhook {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var rets []asana.Webhook
+	for _, w := range s.webhooks.list() {
+		rets = append(rets, *w)
+	}
+	return rets
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, f := range s.failures {
+		if f.method == r.Method && f.path == r.URL.Path {
+			s.failures = append(s.failures[:i], s.failures[i+1:]...)
+			writeError(w, &apiError{code: f.code, message: f.message})
+			return
+		}
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	res, err := s.route(r, parts)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if list, ok := res.([]interface{}); ok {
+		err = writeList(w, r, list)
+	} else {
+		err = writeData(w, r, res)
+	}
+	if err != nil {
+		writeError(w, err)
+	}
+}
+
+func (s *Server) route(r *http.Request, parts []string) (interface{}, error) {
+	q := r.URL.Query()
+	method := r.Method
+	var id asana.GID
+	if len(parts) > 1 {
+		id = asana.GID(parts[1])
+	}
+	switch {
+	case parts[0] == "workspaces" && len(parts) == 1 && method == "GET":
+		return s.listWorkspaces(), nil
+	case parts[0] == "workspaces" && len(parts) == 2 && method == "GET":
+		return s.getWorkspace(id)
+
+	case parts[0] == "users" && len(parts) == 1 && method == "GET":
+		return s.listUsers(asana.GID(q.Get("workspace"))), nil
+	case parts[0] == "users" && len(parts) == 2 && method == "GET":
+		if id == "me" {
+			id = s.me
+		}
+		return s.getUser(id)
+
+	case parts[0] == "projects" && len(parts) == 1 && method == "GET":
+		return s.listProjects(asana.GID(q.Get("workspace")), q.Get("archived")), nil
+	case parts[0] == "projects" && len(parts) == 2 && method == "GET":
+		return s.getProject(id)
+	case parts[0] == "projects" && len(parts) == 3 && parts[2] == "tasks" && method == "GET":
+		return s.listProjectTasks(id)
+	case parts[0] == "projects" && len(parts) == 3 && parts[2] == "sections" && method == "GET":
+		return s.listProjectSections(id)
+
+	case parts[0] == "tags" && len(parts) == 1 && method == "GET":
+		return s.listTags(asana.GID(q.Get("workspace"))), nil
+
+	case parts[0] == "tasks" && len(parts) == 1 && method == "GET":
+		return s.listTasks(q)
+	case parts[0] == "tasks" && len(parts) == 1 && method == "POST":
+		return s.createTask(r)
+	case parts[0] == "tasks" && len(parts) == 2 && method == "GET":
+		return s.getTask(parts[1])
+	case parts[0] == "tasks" && len(parts) == 2 && method == "PUT":
+		return s.updateTask(r, parts[1])
+	case parts[0] == "tasks" && len(parts) == 2 && method == "DELETE":
+		return s.deleteTask(parts[1])
+	case parts[0] == "tasks" && len(parts) == 3 && parts[2] == "stories" && method == "GET":
+		return s.listStories(parts[1])
+	case parts[0] == "tasks" && len(parts) == 3 && method == "POST":
+		return s.taskAction(r, parts[1], parts[2])
+
+	case parts[0] == "sections" && len(parts) == 1 && method == "POST":
+		return s.createSection(r)
+	case parts[0] == "sections" && len(parts) == 2 && method == "GET":
+		return s.getSection(parts[1])
+	case parts[0] == "sections" && len(parts) == 2 && method == "PUT":
+		return s.updateSection(r, parts[1])
+	case parts[0] == "sections" && len(parts) == 2 && method == "DELETE":
+		return s.deleteSection(parts[1])
+
+	case parts[0] == "webhooks" && len(parts) == 1 && method == "GET":
+		return s.listWebhooks(asana.GID(q.Get("workspace")))
+	case parts[0] == "webhooks" && len(parts) == 1 && method == "POST":
+		return s.createWebhook(r)
+	case parts[0] == "webhooks" && len(parts) == 2 && method == "GET":
+		return s.getWebhook(id)
+	case parts[0] == "webhooks" && len(parts) == 2 && method == "DELETE":
+		return s.deleteWebhook(id)
+	}
+	return nil, errorf(http.StatusNotFound, "%s %s: No matching route for request", method, r.URL.Path)
+}
+
+// decodeData decodes the data envelope of a request body into v.
+func decodeData(r *http.Request, v interface{}) error {
+	var body struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return errorf(http.StatusBadRequest, "Could not parse request data: %v", err)
+	}
+	if len(body.Data) == 0 {
+		return errorf(http.StatusBadRequest, "data: Missing input")
+	}
+	if err := json.Unmarshal(body.Data, v); err != nil {
+		return errorf(http.StatusBadRequest, "data: Invalid input: %v", err)
+	}
+	return nil
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	e, ok := err.(*apiError)
+	if !ok {
+		e = &apiError{code: http.StatusInternalServerError, message: err.Error()}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(e.code)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"errors": []asana.Error{{Message: e.message}},
+	})
+}
+
+func writeData(w http.ResponseWriter, r *http.Request, v interface{}) error {
+	data, err := selectFields(v, optFields(r))
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+}
+
+// writeList writes one page of items, selected by the offset and limit
+// query parameters. The offset is the index of the first item.
+func writeList(w http.ResponseWriter, r *http.Request, items []interface{}) error {
+	q := r.URL.Query()
+	limit := defaultLimit
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxLimit {
+			return errorf(http.StatusBadRequest, "limit: Must be between 1 and %d", maxLimit)
+		}
+		limit = n
+	}
+	start := 0
+	if v := q.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 || n > len(items) {
+			return errorf(http.StatusBadRequest, "offset: Your pagination token is invalid.")
+		}
+		start = n
+	}
+	end := start + limit
+	if end > len(items) {
+		end = len(items)
+	}
+
+	fields := optFields(r)
+	data := make([]interface{}, 0, end-start)
+	for _, item := range items[start:end] {
+		v, err := selectFields(item, fields)
+		if err != nil {
+			return err
+		}
+		data = append(data, v)
+	}
+	res := map[string]interface{}{"data": data, "next_page": nil}
+	if end < len(items) {
+		next := *r.URL
+		nq := next.Query()
+		nq.Set("offset", strconv.Itoa(end))
+		next.RawQuery = nq.Encode()
+		res["next_page"] = asana.NextPage{
+			Offset: strconv.Itoa(end),
+			Path:   next.RequestURI(),
+			URI:    "http://" + r.Host + next.RequestURI(),
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(res)
+}
+
+func optFields(r *http.Request) []string {
+	v := r.URL.Query().Get("opt_fields")
+	if v == "" {
+		return nil
+	}
+	return strings.Split(v, ",")
+}
+
+// selectFields returns v with only the gid and the top-level fields named
+// in fields, or v unchanged when fields is empty.
+func selectFields(v interface{}, fields []string) (interface{}, error) {
+	if len(fields) == 0 {
+		return v, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return v, nil
+	}
+	keep := map[string]bool{"gid": true}
+	for _, f := range fields {
+		keep[strings.SplitN(f, ".", 2)[0]] = true
+	}
+	for k := range m {
+		if !keep[k] {
+			delete(m, k)
+		}
+	}
+	return m, nil
+}
+
+func hasProject(projects []asana.Project, gid asana.GID) bool {
+	for _, p := range projects {
+		if p.GID == gid {
+			return true
+		}
+	}
+	return false
+}
+
+// table is an insertion-ordered map of records by gid.
+type table[T any] struct {
+	order []asana.GID
+	rows  map[asana.GID]*T
+}
+
+func (t *table[T]) put(gid asana.GID, v *T) {
+	if t.rows == nil {
+		t.rows = map[asana.GID]*T{}
+	}
+	if _, ok := t.rows[gid]; !ok {
+		t.order = append(t.order, gid)
+	}
+	t.rows[gid] = v
+}
+
+func (t *table[T]) get(gid asana.GID) (*T, bool) {
+	v, ok := t.rows[gid]
+	return v, ok
+}
+
+func (t *table[T]) delete(gid asana.GID) {
+	if _, ok := t.rows[gid]; !ok {
+		return
+	}
+	delete(t.rows, gid)
+	for i, g := range t.order {
+		if g == gid {
+			t.order = append(t.order[:i], t.order[i+1:]...)
+			break
+		}
+	}
+}
+
+func (t *table[T]) list() []*T {
+	rets := make([]*T, 0, len(t.order))
+	for _, gid := range t.order {
+		rets = append(rets, t.rows[gid])
+	}
+	return rets
+}
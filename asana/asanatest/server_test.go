@@ -0,0 +1,177 @@
+package asanatest
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/tambet/go-asana/asana"
+)
+
+func TestServerTasks(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	ctx := context.Background()
+
+	ws := srv.AddWorkspace(asana.Workspace{Name: "Acme"})
+	me := srv.AddUser(asana.User{Name: "Alice", Workspaces: []asana.Workspace{ws}})
+	proj := srv.AddProject(ws.GID, asana.Project{Name: "Roadmap"})
+	tag := srv.AddTag(ws.GID, asana.Tag{Name: "urgent"})
+	client := srv.Client()
+
+	user, err := client.GetAuthenticatedUser(ctx, nil)
+	if err != nil || user.GID != me.GID {
+		t.Fatalf("GetAuthenticatedUser returned %+v, %v", user, err)
+	}
+
+	created, err := client.CreateTask(ctx, map[string]interface{}{
+		"name":     "Ship it",
+		"projects": []asana.GID{proj.GID},
+		"assignee": me.GID,
+		"external": map[string]string{"id": "ext-1"},
+	}, &asana.Filter{OptFields: []string{"name", "projects.name", "assignee.name"}})
+	if err != nil {
+		t.Fatalf("CreateTask returned error: %v", err)
+	}
+	if created.GID == "" || created.Projects[0].Name != "Roadmap" || created.Assignee.Name != "Alice" {
+		t.Errorf("CreateTask returned %+v", created)
+	}
+
+	name := "Ship it now"
+	if _, err := client.UpdateTaskByExternalID(ctx, "ext-1", asana.TaskUpdate{Name: &name}, nil); err != nil {
+		t.Fatalf("UpdateTaskByExternalID returned error: %v", err)
+	}
+	if err := client.AddTag(ctx, created.GID, tag.GID, nil); err != nil {
+		t.Fatalf("AddTag returned error: %v", err)
+	}
+	got, err := client.GetTask(ctx, created.GID, &asana.Filter{OptFields: []string{"name", "tags.name"}})
+	if err != nil {
+		t.Fatalf("GetTask returned error: %v", err)
+	}
+	want := asana.Task{GID: created.GID, Name: name, Tags: []asana.Tag{{GID: tag.GID, Name: "urgent"}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetTask returned %+v, want %+v", got, want)
+	}
+
+	if err := client.DeleteTask(ctx, created.GID, nil); err != nil {
+		t.Fatalf("DeleteTask returned error: %v", err)
+	}
+	if _, err := client.GetTask(ctx, created.GID, nil); !asana.IsNotFound(err) {
+		t.Errorf("GetTask after delete returned %v, want a not found error", err)
+	}
+}
+
+func TestServerPaging(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	ctx := context.Background()
+
+	ws := srv.AddWorkspace(asana.Workspace{Name: "Acme"})
+	proj := srv.AddProject(ws.GID, asana.Project{Name: "Roadmap"})
+	var want []asana.GID
+	for i := 0; i < 5; i++ {
+		task := srv.AddTask(ws.GID, asana.Task{Name: "task", Projects: []asana.Project{{GID: proj.GID}}})
+		want = append(want, task.GID)
+	}
+
+	it := srv.Client().IterProjectTasks(proj.GID, &asana.Filter{Limit: 2})
+	var got []asana.GID
+	for it.Next(ctx) {
+		got = append(got, it.Value().GID)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("IterProjectTasks returned error: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("IterProjectTasks returned %v, want %v", got, want)
+	}
+
+	if _, err := srv.Client().ListTasks(ctx, &asana.Filter{Workspace: ws.GID}); !asana.IsBadRequest(err) {
+		t.Errorf("ListTasks without project or assignee returned %v, want a bad request error", err)
+	}
+}
+
+func TestServerFailNext(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	ctx := context.Background()
+
+	ws := srv.AddWorkspace(asana.Workspace{Name: "Acme"})
+	client := srv.Client(asana.WithRetryPolicy(&asana.RetryPolicy{MaxAttempts: 2, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}))
+
+	srv.FailNext("GET", "/workspaces", 503, "Service Unavailable")
+	got, err := client.ListWorkspaces(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListWorkspaces returned error: %v", err)
+	}
+	if len(got) != 1 || got[0].GID != ws.GID {
+		t.Errorf("ListWorkspaces returned %+v", got)
+	}
+
+	webhook, err := client.CreateWebhook(ctx, ws.GID, "https://example.com/hook")
+	if !asana.IsBadRequest(err) {
+		t.Errorf("CreateWebhook on a workspace without filters returned %+v, %v", webhook, err)
+	}
+}
+
+func TestServerCustomFieldValues(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	ctx := context.Background()
+
+	ws := srv.AddWorkspace(asana.Workspace{Name: "Acme"})
+	alice := srv.AddUser(asana.User{Name: "Alice", Workspaces: []asana.Workspace{ws}})
+	bob := srv.AddUser(asana.User{Name: "Bob", Workspaces: []asana.Workspace{ws}})
+	task := srv.AddTask(ws.GID, asana.Task{Name: "Launch", CustomFields: []asana.CustomField{
+		{GID: "1", Name: "Labels", Type: "multi_enum", EnumOptions: []asana.CFEnumOptions{{GID: "11", Name: "ui"}, {GID: "12", Name: "api"}}},
+		{GID: "2", Name: "Launch date", Type: "date"},
+		{GID: "3", Name: "Reviewers", Type: "people"},
+	}})
+	client := srv.Client()
+
+	var tu asana.TaskUpdate
+	tu.SetCustomField("1", asana.MultiEnumValue("11", "12"))
+	tu.SetCustomField("2", asana.DateValue(asana.CFDateValue{Date: "2024-05-01"}))
+	tu.SetCustomField("3", asana.PeopleValue(alice.GID, bob.GID))
+	if _, err := client.UpdateTask(ctx, task.GID, tu, nil); err != nil {
+		t.Fatalf("UpdateTask returned error: %v", err)
+	}
+
+	get := func() map[asana.GID]asana.CustomField {
+		got, err := client.GetTask(ctx, task.GID, &asana.Filter{OptFields: []string{"custom_fields"}})
+		if err != nil {
+			t.Fatalf("GetTask returned error: %v", err)
+		}
+		fields := map[asana.GID]asana.CustomField{}
+		for _, cf := range got.CustomFields {
+			fields[cf.GID] = cf
+		}
+		return fields
+	}
+	fields := get()
+	if got, want := fields["1"].MultiEnum(), []asana.CFEnumOptions{{GID: "11", Name: "ui"}, {GID: "12", Name: "api"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("multi_enum value = %+v, want %+v", got, want)
+	}
+	if got, ok := fields["2"].Date(); !ok || !got.Equal(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("date value = %v, %v, want 2024-05-01", got, ok)
+	}
+	if got, want := fields["3"].People(), []asana.User{{GID: alice.GID}, {GID: bob.GID}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("people value = %+v, want %+v", got, want)
+	}
+
+	tu = asana.TaskUpdate{}
+	tu.SetCustomField("1", asana.MultiEnumValue())
+	tu.ClearCustomField("2")
+	tu.SetCustomField("3", asana.PeopleValue(bob.GID))
+	if _, err := client.UpdateTask(ctx, task.GID, tu, nil); err != nil {
+		t.Fatalf("UpdateTask returned error: %v", err)
+	}
+	fields = get()
+	if len(fields["1"].MultiEnum()) != 0 || fields["2"].DateValue != nil {
+		t.Errorf("cleared values = %+v, %+v", fields["1"].MultiEnum(), fields["2"].DateValue)
+	}
+	if got, want := fields["3"].People(), []asana.User{{GID: bob.GID}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("people value = %+v, want %+v", got, want)
+	}
+}
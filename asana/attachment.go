@@ -0,0 +1,169 @@
+package asana
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"path/filepath"
+	"strings"
+)
+
+// IterAttachments returns an iterator over the attachments of the task or
+// project set as opt.Parent.
+//
+// https://asana.com/developers/api-reference/attachments#get-all-task
+func (c *Client) IterAttachments(opt *Filter) *AttachmentIterator {
+	return newIterator[Attachment](c, "attachments", opt)
+}
+
+// ListAttachments gets the attachments of the task or project set as opt.Parent.
+//
+// https://asana.com/developers/api-reference/attachments#get-all-task
+func (c *Client) ListAttachments(ctx context.Context, opt *Filter) ([]Attachment, error) {
+	return listAll(ctx, c.IterAttachments(opt))
+}
+
+// GetAttachment gets an attachment.
+//
+// https://asana.com/developers/api-reference/attachments#get-single
+func (c *Client) GetAttachment(ctx context.Context, gid GID, opt *Filter) (Attachment, error) {
+	attachment := new(Attachment)
+	err := c.Request(ctx, fmt.Sprintf("attachments/%s", gid), opt, attachment)
+	return *attachment, err
+}
+
+// DeleteAttachment deletes an attachment.
+//
+// https://asana.com/developers/api-reference/attachments#delete
+func (c *Client) DeleteAttachment(ctx context.Context, gid GID) error {
+	_, err := c.request(ctx, "DELETE", fmt.Sprintf("attachments/%s", gid), nil, nil, nil, nil)
+	return err
+}
+
+// UploadAttachment uploads the content of r as a file named name and
+// attaches it to a task or project.
+//
+// The content is streamed as it is read, never held in memory, so the
+// request cannot be replayed: it is sent once, regardless of c.Retry.
+//
+// https://asana.com/developers/api-reference/attachments#upload
+func (c *Client) UploadAttachment(ctx context.Context, parentGID GID, name string, r io.Reader, opts *Filter) (Attachment, error) {
+	attachment := new(Attachment)
+	err := c.postMultipart(ctx, "attachments", [][2]string{{"parent", string(parentGID)}}, &multipartFile{name: name, r: r}, opts, attachment)
+	return *attachment, err
+}
+
+// CreateExternalAttachment attaches a link to url, named name, to a task
+// or project.
+//
+// https://asana.com/developers/api-reference/attachments#upload
+func (c *Client) CreateExternalAttachment(ctx context.Context, parentGID GID, name, url string, opts *Filter) (Attachment, error) {
+	attachment := new(Attachment)
+	fields := [][2]string{
+		{"parent", string(parentGID)},
+		{"resource_subtype", "external"},
+		{"name", name},
+		{"url", url},
+	}
+	err := c.postMultipart(ctx, "attachments", fields, nil, opts, attachment)
+	return *attachment, err
+}
+
+// DownloadAttachment returns the content of an attachment hosted by Asana.
+// The caller must close it.
+//
+// The content is fetched from the download_url of the attachment through
+// the Doer of the client. That URL is signed: a Doer adding credentials to
+// requests must only add them for the Asana API.
+func (c *Client) DownloadAttachment(ctx context.Context, gid GID) (io.ReadCloser, error) {
+	attachment, err := c.GetAttachment(ctx, gid, &Filter{OptFields: []string{"download_url"}})
+	if err != nil {
+		return nil, err
+	}
+	if attachment.DownloadURL == "" {
+		return nil, fmt.Errorf("asana: attachment %s has no download url", gid)
+	}
+	req, err := http.NewRequest("GET", attachment.DownloadURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", c.UserAgent)
+	resp, err := c.doer.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("asana: downloading attachment %s: %s", gid, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+type multipartFile struct {
+	name string
+	r    io.Reader
+}
+
+// postMultipart posts fields, and file if not nil, as a multipart form.
+// The body is written while it is sent, so the request is sent only once.
+func (c *Client) postMultipart(ctx context.Context, path string, fields [][2]string, file *multipartFile, opt *Filter, v interface{}) error {
+	u, err := c.resolve(path, withDefaultOptFields(path, opt))
+	if err != nil {
+		return err
+	}
+
+	pr, pw := io.Pipe()
+	defer pr.Close()
+	mw := multipart.NewWriter(pw)
+	go func() {
+		pw.CloseWithError(writeMultipart(mw, fields, file))
+	}()
+
+	req, err := http.NewRequest("POST", u, pr)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	req.Header.Set("User-Agent", c.UserAgent)
+	resp, err := c.send(ctx, req)
+	if err != nil {
+		return err
+	}
+	_, err = decodeResponse(resp, v)
+	return err
+}
+
+func writeMultipart(mw *multipart.Writer, fields [][2]string, file *multipartFile) error {
+	for _, f := range fields {
+		if err := mw.WriteField(f[0], f[1]); err != nil {
+			return err
+		}
+	}
+	if file != nil {
+		contentType := mime.TypeByExtension(filepath.Ext(file.name))
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`, escapeQuotes(file.name)))
+		h.Set("Content-Type", contentType)
+		part, err := mw.CreatePart(h)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(part, file.r); err != nil {
+			return err
+		}
+	}
+	return mw.Close()
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func escapeQuotes(s string) string {
+	return quoteEscaper.Replace(s)
+}
@@ -0,0 +1,229 @@
+package asana
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Number returns the value of a number field.
+func (cf CustomField) Number() (float64, bool) {
+	if cf.NumberValue == nil {
+		return 0, false
+	}
+	return *cf.NumberValue, true
+}
+
+// Enum returns the selected option of an enum field.
+func (cf CustomField) Enum() (CFEnumOptions, bool) {
+	if cf.EnumValue == nil {
+		return CFEnumOptions{}, false
+	}
+	return *cf.EnumValue, true
+}
+
+// MultiEnum returns the selected options of a multi_enum field.
+func (cf CustomField) MultiEnum() []CFEnumOptions {
+	return cf.MultiEnumValues
+}
+
+// Date returns the value of a date field. Dates without a time are
+// returned as midnight UTC.
+func (cf CustomField) Date() (time.Time, bool) {
+	if cf.DateValue == nil {
+		return time.Time{}, false
+	}
+	if cf.DateValue.DateTime != "" {
+		if t, err := time.Parse(time.RFC3339, cf.DateValue.DateTime); err == nil {
+			return t, true
+		}
+	}
+	t, err := time.Parse("2006-01-02", cf.DateValue.Date)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// People returns the users of a people field.
+func (cf CustomField) People() []User {
+	return cf.PeopleValue
+}
+
+// CustomFieldValue is the value of a custom field in a TaskUpdate,
+// marshaled in the shape its type expects. Build it with TextValue,
+// NumberValue, EnumValue, MultiEnumValue, DateValue or PeopleValue.
+type CustomFieldValue struct {
+	v interface{}
+}
+
+func (v CustomFieldValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.v)
+}
+
+// TextValue is the value of a text field.
+func TextValue(s string) CustomFieldValue {
+	return CustomFieldValue{s}
+}
+
+// NumberValue is the value of a number field.
+func NumberValue(n float64) CustomFieldValue {
+	return CustomFieldValue{n}
+}
+
+// EnumValue selects an option of an enum field.
+func EnumValue(option GID) CustomFieldValue {
+	return CustomFieldValue{option}
+}
+
+// MultiEnumValue selects options of a multi_enum field. No options clears it.
+func MultiEnumValue(options ...GID) CustomFieldValue {
+	return CustomFieldValue{append([]GID{}, options...)}
+}
+
+// DateValue is the value of a date field, with a date or a date and time.
+func DateValue(date CFDateValue) CustomFieldValue {
+	return CustomFieldValue{date}
+}
+
+// PeopleValue sets the users of a people field. No users clears it.
+func PeopleValue(users ...GID) CustomFieldValue {
+	return CustomFieldValue{append([]GID{}, users...)}
+}
+
+// SetCustomField sets the value of a custom field in the update.
+func (u *TaskUpdate) SetCustomField(field GID, v CustomFieldValue) {
+	if u.CustomFields == nil {
+		u.CustomFields = map[GID]interface{}{}
+	}
+	u.CustomFields[field] = v
+}
+
+// ClearCustomField clears the value of a custom field in the update.
+func (u *TaskUpdate) ClearCustomField(field GID) {
+	if u.CustomFields == nil {
+		u.CustomFields = map[GID]interface{}{}
+	}
+	u.CustomFields[field] = nil
+}
+
+// formatValue formats the value of the field as text, "" when it is unset.
+func (cf CustomField) formatValue() string {
+	switch cf.Type {
+	case "text":
+		return cf.TextValue
+	case "number":
+		if v, ok := cf.Number(); ok {
+			return strconv.FormatFloat(v, 'f', int(cf.Precision), 64)
+		}
+		return ""
+	case "enum":
+		if v, ok := cf.Enum(); ok {
+			return v.Name
+		}
+		return ""
+	case "multi_enum":
+		names := make([]string, len(cf.MultiEnumValues))
+		for i, o := range cf.MultiEnumValues {
+			names[i] = o.Name
+		}
+		return strings.Join(names, ", ")
+	case "date":
+		if cf.DateValue == nil {
+			return ""
+		}
+		if cf.DateValue.DateTime != "" {
+			return cf.DateValue.DateTime
+		}
+		return cf.DateValue.Date
+	case "people":
+		names := make([]string, len(cf.PeopleValue))
+		for i, u := range cf.PeopleValue {
+			names[i] = u.Name
+		}
+		return strings.Join(names, ", ")
+	}
+	return cf.DisplayValue
+}
+
+// GetCustomField gets a custom field.
+//
+// https://asana.com/developers/api-reference/custom_fields#get-single
+func (c *Client) GetCustomField(ctx context.Context, gid GID, opt *Filter) (CustomField, error) {
+	field := new(CustomField)
+	err := c.Request(ctx, fmt.Sprintf("custom_fields/%s", gid), opt, field)
+	return *field, err
+}
+
+// IterWorkspaceCustomFields returns an iterator over the custom fields of a workspace.
+//
+// https://asana.com/developers/api-reference/custom_fields#query-metadata
+func (c *Client) IterWorkspaceCustomFields(workspaceGID GID, opt *Filter) *CustomFieldIterator {
+	return newIterator[CustomField](c, fmt.Sprintf("workspaces/%s/custom_fields", workspaceGID), opt)
+}
+
+// ListWorkspaceCustomFields gets the custom fields of a workspace.
+//
+// https://asana.com/developers/api-reference/custom_fields#query-metadata
+func (c *Client) ListWorkspaceCustomFields(ctx context.Context, workspaceGID GID, opt *Filter) ([]CustomField, error) {
+	return listAll(ctx, c.IterWorkspaceCustomFields(workspaceGID, opt))
+}
+
+// CreateCustomField creates a custom field. fields must include the
+// workspace, name and resource_subtype.
+//
+// https://asana.com/developers/api-reference/custom_fields#create
+func (c *Client) CreateCustomField(ctx context.Context, fields map[string]interface{}, opts *Filter) (CustomField, error) {
+	field := new(CustomField)
+	_, err := c.request(ctx, "POST", "custom_fields", fields, nil, opts, field)
+	return *field, err
+}
+
+// UpdateCustomField updates a custom field.
+//
+// https://asana.com/developers/api-reference/custom_fields#update
+func (c *Client) UpdateCustomField(ctx context.Context, gid GID, cu CustomFieldUpdate, opt *Filter) (CustomField, error) {
+	field := new(CustomField)
+	_, err := c.request(ctx, "PUT", fmt.Sprintf("custom_fields/%s", gid), cu, nil, opt, field)
+	return *field, err
+}
+
+// DeleteCustomField deletes a custom field.
+//
+// https://asana.com/developers/api-reference/custom_fields#delete
+func (c *Client) DeleteCustomField(ctx context.Context, gid GID) error {
+	_, err := c.request(ctx, "DELETE", fmt.Sprintf("custom_fields/%s", gid), nil, nil, nil, nil)
+	return err
+}
+
+// CreateEnumOption adds an option to an enum or multi_enum field.
+// fields must include the name, and may include insert_before or insert_after.
+//
+// https://asana.com/developers/api-reference/custom_fields#create-option
+func (c *Client) CreateEnumOption(ctx context.Context, fieldGID GID, fields map[string]interface{}, opts *Filter) (CFEnumOptions, error) {
+	option := new(CFEnumOptions)
+	_, err := c.request(ctx, "POST", fmt.Sprintf("custom_fields/%s/enum_options", fieldGID), fields, nil, opts, option)
+	return *option, err
+}
+
+// UpdateEnumOption updates an enum option. Options cannot be deleted, only
+// disabled.
+//
+// https://asana.com/developers/api-reference/custom_fields#update-option
+func (c *Client) UpdateEnumOption(ctx context.Context, gid GID, eu EnumOptionUpdate, opt *Filter) (CFEnumOptions, error) {
+	option := new(CFEnumOptions)
+	_, err := c.request(ctx, "PUT", fmt.Sprintf("enum_options/%s", gid), eu, nil, opt, option)
+	return *option, err
+}
+
+// InsertEnumOption moves an enum option of a field.
+//
+// https://asana.com/developers/api-reference/custom_fields#reorder-options
+func (c *Client) InsertEnumOption(ctx context.Context, fieldGID GID, ei EnumOptionInsert, opt *Filter) (CFEnumOptions, error) {
+	option := new(CFEnumOptions)
+	_, err := c.request(ctx, "POST", fmt.Sprintf("custom_fields/%s/enum_options/insert", fieldGID), ei, nil, opt, option)
+	return *option, err
+}
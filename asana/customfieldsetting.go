@@ -0,0 +1,96 @@
+package asana
+
+import (
+	"context"
+	"fmt"
+)
+
+// IterProjectCustomFieldSettings returns an iterator over the custom fields attached to a project.
+//
+// https://asana.com/developers/api-reference/custom_field_settings#query-project
+func (c *Client) IterProjectCustomFieldSettings(projectGID GID, opt *Filter) *CustomFieldSettingIterator {
+	return newIterator[CustomFieldSetting](c, fmt.Sprintf("projects/%s/custom_field_settings", projectGID), opt)
+}
+
+// ListProjectCustomFieldSettings gets the custom fields attached to a project.
+//
+// https://asana.com/developers/api-reference/custom_field_settings#query-project
+func (c *Client) ListProjectCustomFieldSettings(ctx context.Context, projectGID GID, opt *Filter) ([]CustomFieldSetting, error) {
+	return listAll(ctx, c.IterProjectCustomFieldSettings(projectGID, opt))
+}
+
+// AddProjectCustomField attaches a custom field to a project.
+//
+// https://asana.com/developers/api-reference/projects#custom-field-settings
+func (c *Client) AddProjectCustomField(ctx context.Context, projectGID GID, cu CustomFieldSettingUpdate, opts *Filter) (CustomFieldSetting, error) {
+	setting := new(CustomFieldSetting)
+	_, err := c.request(ctx, "POST", fmt.Sprintf("projects/%s/addCustomFieldSetting", projectGID), cu, nil, opts, setting)
+	return *setting, err
+}
+
+// RemoveProjectCustomField detaches a custom field from a project.
+//
+// https://asana.com/developers/api-reference/projects#custom-field-settings
+func (c *Client) RemoveProjectCustomField(ctx context.Context, projectGID GID, fieldGID GID, opts *Filter) error {
+	_, err := c.request(ctx, "POST", fmt.Sprintf("projects/%s/removeCustomFieldSetting", projectGID), map[string]interface{}{"custom_field": fieldGID}, nil, opts, nil)
+	return err
+}
+
+// IterPortfolioCustomFieldSettings returns an iterator over the custom fields attached to a portfolio.
+//
+// https://asana.com/developers/api-reference/custom_field_settings#query-portfolio
+func (c *Client) IterPortfolioCustomFieldSettings(portfolioGID GID, opt *Filter) *CustomFieldSettingIterator {
+	return newIterator[CustomFieldSetting](c, fmt.Sprintf("portfolios/%s/custom_field_settings", portfolioGID), opt)
+}
+
+// ListPortfolioCustomFieldSettings gets the custom fields attached to a portfolio.
+//
+// https://asana.com/developers/api-reference/custom_field_settings#query-portfolio
+func (c *Client) ListPortfolioCustomFieldSettings(ctx context.Context, portfolioGID GID, opt *Filter) ([]CustomFieldSetting, error) {
+	return listAll(ctx, c.IterPortfolioCustomFieldSettings(portfolioGID, opt))
+}
+
+// AddPortfolioCustomField attaches a custom field to a portfolio.
+//
+// https://asana.com/developers/api-reference/portfolios#custom-field-settings
+func (c *Client) AddPortfolioCustomField(ctx context.Context, portfolioGID GID, cu CustomFieldSettingUpdate, opts *Filter) (CustomFieldSetting, error) {
+	setting := new(CustomFieldSetting)
+	_, err := c.request(ctx, "POST", fmt.Sprintf("portfolios/%s/addCustomFieldSetting", portfolioGID), cu, nil, opts, setting)
+	return *setting, err
+}
+
+// RemovePortfolioCustomField detaches a custom field from a portfolio.
+//
+// https://asana.com/developers/api-reference/portfolios#custom-field-settings
+func (c *Client) RemovePortfolioCustomField(ctx context.Context, portfolioGID GID, fieldGID GID, opts *Filter) error {
+	_, err := c.request(ctx, "POST", fmt.Sprintf("portfolios/%s/removeCustomFieldSetting", portfolioGID), map[string]interface{}{"custom_field": fieldGID}, nil, opts, nil)
+	return err
+}
+
+// EnsureProjectCustomFields attaches the custom fields of settings to a
+// project unless they already are, in order. It returns the settings it
+// added; fields already attached are left as they are.
+func (c *Client) EnsureProjectCustomFields(ctx context.Context, projectGID GID, settings []CustomFieldSettingUpdate) ([]CustomFieldSetting, error) {
+	existing, err := c.ListProjectCustomFieldSettings(ctx, projectGID, &Filter{OptFields: []string{"custom_field", "is_important"}})
+	if err != nil {
+		return nil, err
+	}
+	attached := map[GID]bool{}
+	for _, s := range existing {
+		attached[s.CustomField.GID] = true
+	}
+
+	var added []CustomFieldSetting
+	for _, cu := range settings {
+		if attached[cu.CustomField] {
+			continue
+		}
+		s, err := c.AddProjectCustomField(ctx, projectGID, cu, nil)
+		if err != nil {
+			return added, fmt.Errorf("asana: adding custom field %s to project %s: %w", cu.CustomField, projectGID, err)
+		}
+		attached[cu.CustomField] = true
+		added = append(added, s)
+	}
+	return added, nil
+}
@@ -0,0 +1,283 @@
+package asana
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// IterDependencies returns an iterator over the tasks a task depends on.
+//
+// https://asana.com/developers/api-reference/tasks#dependencies
+func (c *Client) IterDependencies(taskGID GID, opt *Filter) *TaskIterator {
+	return newIterator[Task](c, fmt.Sprintf("tasks/%s/dependencies", taskGID), opt)
+}
+
+// ListDependencies gets the tasks a task depends on.
+//
+// https://asana.com/developers/api-reference/tasks#dependencies
+func (c *Client) ListDependencies(ctx context.Context, taskGID GID, opt *Filter) ([]Task, error) {
+	return listAll(ctx, c.IterDependencies(taskGID, opt))
+}
+
+// IterDependents returns an iterator over the tasks depending on a task.
+//
+// https://asana.com/developers/api-reference/tasks#dependents
+func (c *Client) IterDependents(taskGID GID, opt *Filter) *TaskIterator {
+	return newIterator[Task](c, fmt.Sprintf("tasks/%s/dependents", taskGID), opt)
+}
+
+// ListDependents gets the tasks depending on a task.
+//
+// https://asana.com/developers/api-reference/tasks#dependents
+func (c *Client) ListDependents(ctx context.Context, taskGID GID, opt *Filter) ([]Task, error) {
+	return listAll(ctx, c.IterDependents(taskGID, opt))
+}
+
+// AddDependencies makes a task depend on other tasks.
+//
+// https://asana.com/developers/api-reference/tasks#dependencies
+func (c *Client) AddDependencies(ctx context.Context, taskGID GID, dependencyGIDs []GID, opts *Filter) error {
+	_, err := c.request(ctx, "POST", fmt.Sprintf("tasks/%s/addDependencies", taskGID), map[string]interface{}{"dependencies": dependencyGIDs}, nil, opts, nil)
+	return err
+}
+
+// RemoveDependencies makes a task no longer depend on other tasks.
+//
+// https://asana.com/developers/api-reference/tasks#dependencies
+func (c *Client) RemoveDependencies(ctx context.Context, taskGID GID, dependencyGIDs []GID, opts *Filter) error {
+	_, err := c.request(ctx, "POST", fmt.Sprintf("tasks/%s/removeDependencies", taskGID), map[string]interface{}{"dependencies": dependencyGIDs}, nil, opts, nil)
+	return err
+}
+
+// AddDependents makes other tasks depend on a task.
+//
+// https://asana.com/developers/api-reference/tasks#dependents
+func (c *Client) AddDependents(ctx context.Context, taskGID GID, dependentGIDs []GID, opts *Filter) error {
+	_, err := c.request(ctx, "POST", fmt.Sprintf("tasks/%s/addDependents", taskGID), map[string]interface{}{"dependents": dependentGIDs}, nil, opts, nil)
+	return err
+}
+
+// RemoveDependents makes other tasks no longer depend on a task.
+//
+// https://asana.com/developers/api-reference/tasks#dependents
+func (c *Client) RemoveDependents(ctx context.Context, taskGID GID, dependentGIDs []GID, opts *Filter) error {
+	_, err := c.request(ctx, "POST", fmt.Sprintf("tasks/%s/removeDependents", taskGID), map[string]interface{}{"dependents": dependentGIDs}, nil, opts, nil)
+	return err
+}
+
+// CycleError is returned when the dependencies of tasks form a cycle.
+type CycleError struct {
+	// Cycle lists the tasks of the cycle, each depending on the next one
+	// and the last one on the first.
+	Cycle []GID
+}
+
+func (e *CycleError) Error() string {
+	s := make([]string, 0, len(e.Cycle)+1)
+	for _, gid := range e.Cycle {
+		s = append(s, string(gid))
+	}
+	if len(e.Cycle) > 0 {
+		s = append(s, string(e.Cycle[0]))
+	}
+	return "asana: dependency cycle: " + strings.Join(s, " -> ")
+}
+
+// DependencyGraph is the graph of the dependencies between a set of tasks.
+// Dependencies on tasks outside the set are ignored.
+type DependencyGraph struct {
+	tasks        map[GID]Task
+	order        []GID
+	dependencies map[GID][]GID
+	dependents   map[GID][]GID
+}
+
+// NewDependencyGraph returns the graph of the dependencies of tasks, read
+// from their Dependencies field.
+func NewDependencyGraph(tasks []Task) *DependencyGraph {
+	g := &DependencyGraph{
+		tasks:        map[GID]Task{},
+		dependencies: map[GID][]GID{},
+		dependents:   map[GID][]GID{},
+	}
+	for _, t := range tasks {
+		if _, ok := g.tasks[t.GID]; !ok {
+			g.order = append(g.order, t.GID)
+		}
+		g.tasks[t.GID] = t
+	}
+	for _, gid := range g.order {
+		for _, dep := range g.tasks[gid].Dependencies {
+			if _, ok := g.tasks[dep.GID]; !ok {
+				continue
+			}
+			g.dependencies[gid] = append(g.dependencies[gid], dep.GID)
+			g.dependents[dep.GID] = append(g.dependents[dep.GID], gid)
+		}
+	}
+	return g
+}
+
+// GetProjectDependencyGraph gets the graph of the dependencies between the
+// tasks of a project.
+func (c *Client) GetProjectDependencyGraph(ctx context.Context, projectGID GID) (*DependencyGraph, error) {
+	tasks, err := c.ListProjectTasks(ctx, projectGID, &Filter{
+		OptFields: []string{"name", "completed", "start_on", "due_on", "due_at", "dependencies"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return NewDependencyGraph(tasks), nil
+}
+
+// Task returns a task of the graph.
+func (g *DependencyGraph) Task(gid GID) (Task, bool) {
+	t, ok := g.tasks[gid]
+	return t, ok
+}
+
+// Dependencies returns the tasks of the graph a task depends on.
+func (g *DependencyGraph) Dependencies(gid GID) []GID {
+	return g.dependencies[gid]
+}
+
+// Dependents returns the tasks of the graph depending on a task.
+func (g *DependencyGraph) Dependents(gid GID) []GID {
+	return g.dependents[gid]
+}
+
+// TopologicalOrder returns the tasks ordered so that every task comes after
+// the tasks it depends on. Independent tasks keep the order they were given
+// in. It returns a *CycleError if the dependencies form a cycle.
+func (g *DependencyGraph) TopologicalOrder() ([]Task, error) {
+	pending := map[GID]int{}
+	for _, gid := range g.order {
+		pending[gid] = len(g.dependencies[gid])
+	}
+	done := map[GID]bool{}
+	rets := make([]Task, 0, len(g.order))
+	for len(rets) < len(g.order) {
+		progress := false
+		for _, gid := range g.order {
+			if done[gid] || pending[gid] > 0 {
+				continue
+			}
+			done[gid] = true
+			progress = true
+			rets = append(rets, g.tasks[gid])
+			for _, dep := range g.dependents[gid] {
+				pending[dep]--
+			}
+		}
+		if !progress {
+			return nil, &CycleError{Cycle: g.findCycle(done)}
+		}
+	}
+	return rets, nil
+}
+
+// findCycle returns a cycle among the tasks not done.
+func (g *DependencyGraph) findCycle(done map[GID]bool) []GID {
+	// Every task left has a dependency left: follow them until one repeats.
+	var start GID
+	for _, gid := range g.order {
+		if !done[gid] {
+			start = gid
+			break
+		}
+	}
+	index := map[GID]int{}
+	var path []GID
+	for gid := start; ; {
+		if i, ok := index[gid]; ok {
+			return path[i:]
+		}
+		index[gid] = len(path)
+		path = append(path, gid)
+		for _, dep := range g.dependencies[gid] {
+			if !done[dep] {
+				gid = dep
+				break
+			}
+		}
+	}
+}
+
+// CriticalPath returns the longest chain of dependencies: the tasks whose
+// delay would push back the end of the others. A task counts for the days
+// from its start date to its due date, or for one day without both. Among
+// chains of the same length, the one due last is returned. It starts with a
+// task without dependencies. It returns a *CycleError if the dependencies
+// form a cycle.
+func (g *DependencyGraph) CriticalPath() ([]Task, error) {
+	order, err := g.TopologicalOrder()
+	if err != nil {
+		return nil, err
+	}
+	length := map[GID]int{}
+	longer := func(a, b GID) bool {
+		if length[a] != length[b] {
+			return length[a] > length[b]
+		}
+		return taskDue(g.tasks[a]).After(taskDue(g.tasks[b]))
+	}
+
+	// Dependencies come first in order, so their longest chain is known.
+	prev := map[GID]GID{}
+	var last GID
+	for _, t := range order {
+		var best GID
+		for _, dep := range g.dependencies[t.GID] {
+			if best == "" || longer(dep, best) {
+				best = dep
+			}
+		}
+		length[t.GID] = taskDays(t)
+		if best != "" {
+			length[t.GID] += length[best]
+			prev[t.GID] = best
+		}
+		if last == "" || longer(t.GID, last) {
+			last = t.GID
+		}
+	}
+	if last == "" {
+		return nil, nil
+	}
+
+	var path []Task
+	for gid := last; gid != ""; gid = prev[gid] {
+		path = append(path, g.tasks[gid])
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path, nil
+}
+
+// taskDays returns the number of days a task spans, at least one.
+func taskDays(t Task) int {
+	start, err := time.Parse("2006-01-02", t.StartOn)
+	due := taskDue(t)
+	if err != nil || due.IsZero() || due.Before(start) {
+		return 1
+	}
+	return int(due.Sub(start)/(24*time.Hour)) + 1
+}
+
+// taskDue returns when a task is due, the zero time if it has no due date.
+func taskDue(t Task) time.Time {
+	if t.DueAt != "" {
+		if at, err := time.Parse(time.RFC3339, t.DueAt); err == nil {
+			return at
+		}
+	}
+	if t.DueOn != "" {
+		if on, err := time.Parse("2006-01-02", t.DueOn); err == nil {
+			return on
+		}
+	}
+	return time.Time{}
+}
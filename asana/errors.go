@@ -0,0 +1,150 @@
+package asana
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type (
+	// BadRequestError is returned on response status code 400.
+	BadRequestError struct{ *Errors }
+
+	// PaymentRequiredError is returned on response status code 402,
+	// when the request needs a premium feature.
+	PaymentRequiredError struct{ *Errors }
+
+	// ForbiddenError is returned on response status code 403.
+	ForbiddenError struct{ *Errors }
+
+	// NotFoundError is returned on response status code 404.
+	NotFoundError struct{ *Errors }
+
+	// ConflictError is returned on response status code 409.
+	ConflictError struct{ *Errors }
+
+	// PreconditionFailedError is returned on response status code 412.
+	PreconditionFailedError struct{ *Errors }
+
+	// RateLimitError is returned on response status code 429.
+	RateLimitError struct {
+		*Errors
+		// RetryAfter is the delay requested by the Retry-After header.
+		RetryAfter time.Duration
+	}
+
+	// ServerError is returned on 5xx response status codes.
+	ServerError struct{ *Errors }
+
+	// DecodeError is returned when a successful response body is not valid JSON.
+	DecodeError struct {
+		Code      int
+		RequestID string
+		Body      []byte
+		Err       error
+	}
+)
+
+func (e *BadRequestError) Unwrap() error         { return e.Errors }
+func (e *PaymentRequiredError) Unwrap() error    { return e.Errors }
+func (e *ForbiddenError) Unwrap() error          { return e.Errors }
+func (e *NotFoundError) Unwrap() error           { return e.Errors }
+func (e *ConflictError) Unwrap() error           { return e.Errors }
+func (e *PreconditionFailedError) Unwrap() error { return e.Errors }
+func (e *RateLimitError) Unwrap() error          { return e.Errors }
+func (e *ServerError) Unwrap() error             { return e.Errors }
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("asana: decoding response (code: %d, request id: %s): %v", e.Code, e.RequestID, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// newError wraps the errors of a failed response into the kind matching its status code.
+// body is the raw response body, kept when it could not be decoded.
+func newError(resp *http.Response, errs []Error, body []byte) error {
+	e := &Errors{
+		Errors:    errs,
+		Code:      resp.StatusCode,
+		RequestID: resp.Header.Get("X-Request-Id"),
+		Body:      body,
+	}
+	switch resp.StatusCode {
+	case http.StatusBadRequest:
+		return &BadRequestError{e}
+	case http.StatusPaymentRequired:
+		return &PaymentRequiredError{e}
+	case http.StatusForbidden:
+		return &ForbiddenError{e}
+	case http.StatusNotFound:
+		return &NotFoundError{e}
+	case http.StatusConflict:
+		return &ConflictError{e}
+	case http.StatusPreconditionFailed:
+		return &PreconditionFailedError{e}
+	case http.StatusTooManyRequests:
+		d, _ := retryAfter(resp.Header.Get("Retry-After"))
+		return &RateLimitError{Errors: e, RetryAfter: d}
+	}
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return &ServerError{e}
+	}
+	return e
+}
+
+// IsBadRequest reports whether err was caused by response status code 400.
+func IsBadRequest(err error) bool {
+	var e *BadRequestError
+	return errors.As(err, &e)
+}
+
+// IsPaymentRequired reports whether err was caused by response status code 402.
+func IsPaymentRequired(err error) bool {
+	var e *PaymentRequiredError
+	return errors.As(err, &e)
+}
+
+// IsForbidden reports whether err was caused by response status code 403.
+func IsForbidden(err error) bool {
+	var e *ForbiddenError
+	return errors.As(err, &e)
+}
+
+// IsNotFound reports whether err was caused by response status code 404.
+func IsNotFound(err error) bool {
+	var e *NotFoundError
+	return errors.As(err, &e)
+}
+
+// IsConflict reports whether err was caused by response status code 409.
+func IsConflict(err error) bool {
+	var e *ConflictError
+	return errors.As(err, &e)
+}
+
+// IsPreconditionFailed reports whether err was caused by response status code 412.
+func IsPreconditionFailed(err error) bool {
+	var e *PreconditionFailedError
+	return errors.As(err, &e)
+}
+
+// IsRateLimited reports whether err was caused by response status code 429.
+func IsRateLimited(err error) bool {
+	var e *RateLimitError
+	return errors.As(err, &e)
+}
+
+// IsServerError reports whether err was caused by a 5xx response status code.
+func IsServerError(err error) bool {
+	var e *ServerError
+	return errors.As(err, &e)
+}
+
+// IsRetryable reports whether the request that failed with err may succeed
+// when sent again later.
+func IsRetryable(err error) bool {
+	return IsRateLimited(err) || IsServerError(err)
+}
@@ -0,0 +1,273 @@
+package asana
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+type (
+	// RoutedEvent is an event passed to the handlers of an EventRouter.
+	RoutedEvent struct {
+		Event
+		// Task is the task the event is about, or the parent task of a story,
+		// when the router hydrates tasks. It is nil for deleted tasks.
+		Task *Task
+	}
+
+	// EventHandler handles a routed event.
+	EventHandler func(ctx context.Context, e *RoutedEvent) error
+
+	// EventMiddleware wraps the handling of every event dispatched by an EventRouter.
+	EventMiddleware func(next EventHandler) EventHandler
+
+	// EventMatch selects the events a handler is registered for.
+	// Empty fields match any value.
+	EventMatch struct {
+		ResourceType    string
+		ResourceSubtype string
+		Action          string
+		// ParentType is the resource type of the event parent, e.g. "project"
+		// for a task added to or removed from a project.
+		ParentType string
+		// Fields restricts "changed" events to changes of these fields.
+		Fields []string
+	}
+
+	// EventRouter dispatches events, from a webhook or the events API, to the
+	// handlers registered for their resource type and action.
+	EventRouter struct {
+		client *Client
+		// HydrateTasks makes the router fetch the task of task and story
+		// events with GetTask before calling handlers.
+		HydrateTasks bool
+		// TaskOptions is the filter hydrated tasks are fetched with.
+		TaskOptions *Filter
+
+		routes     []route
+		middleware []EventMiddleware
+	}
+
+	route struct {
+		match   EventMatch
+		handler EventHandler
+	}
+)
+
+// NewEventRouter returns a router fetching hydrated resources with c.
+// c may be nil when hydration is not used.
+func NewEventRouter(c *Client) *EventRouter {
+	return &EventRouter{client: c}
+}
+
+// Use appends middleware wrapping the handling of every event.
+// The first middleware is the outermost one.
+func (r *EventRouter) Use(mw ...EventMiddleware) {
+	r.middleware = append(r.middleware, mw...)
+}
+
+// Handle registers h for the events matching m.
+func (r *EventRouter) Handle(m EventMatch, h EventHandler) {
+	r.routes = append(r.routes, route{match: m, handler: h})
+}
+
+// OnTaskAdded registers h for tasks added to any parent, including new tasks.
+func (r *EventRouter) OnTaskAdded(h EventHandler) {
+	r.Handle(EventMatch{ResourceType: "task", Action: "added"}, h)
+}
+
+// OnTaskChanged registers h for changed tasks, optionally only for changes of fields.
+func (r *EventRouter) OnTaskChanged(h EventHandler, fields ...string) {
+	r.Handle(EventMatch{ResourceType: "task", Action: "changed", Fields: fields}, h)
+}
+
+// OnTaskDeleted registers h for deleted tasks.
+func (r *EventRouter) OnTaskDeleted(h EventHandler) {
+	r.Handle(EventMatch{ResourceType: "task", Action: "deleted"}, h)
+}
+
+// OnTaskAddedToProject registers h for tasks added to a project.
+func (r *EventRouter) OnTaskAddedToProject(h EventHandler) {
+	r.Handle(EventMatch{ResourceType: "task", Action: "added", ParentType: "project"}, h)
+}
+
+// OnTaskRemovedFromProject registers h for tasks removed from a project.
+func (r *EventRouter) OnTaskRemovedFromProject(h EventHandler) {
+	r.Handle(EventMatch{ResourceType: "task", Action: "removed", ParentType: "project"}, h)
+}
+
+// OnStoryAdded registers h for stories added to a task, such as comments.
+func (r *EventRouter) OnStoryAdded(h EventHandler) {
+	r.Handle(EventMatch{ResourceType: "story", Action: "added"}, h)
+}
+
+// OnProjectChanged registers h for changed projects, optionally only for changes of fields.
+func (r *EventRouter) OnProjectChanged(h EventHandler, fields ...string) {
+	r.Handle(EventMatch{ResourceType: "project", Action: "changed", Fields: fields}, h)
+}
+
+// Dispatch passes every event to the handlers it matches. It stops at the
+// first error, which is returned.
+// Its signature matches WebhookHandler.OnEvents and EventConsumer.Handle.
+func (r *EventRouter) Dispatch(ctx context.Context, events []Event) error {
+	h := EventHandler(r.route)
+	for i := len(r.middleware) - 1; i >= 0; i-- {
+		h = r.middleware[i](h)
+	}
+	for _, e := range events {
+		if err := h(ctx, &RoutedEvent{Event: e}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *EventRouter) route(ctx context.Context, e *RoutedEvent) error {
+	hydrated := false
+	for _, rt := range r.routes {
+		if !rt.match.matches(e.Event) {
+			continue
+		}
+		if r.HydrateTasks && !hydrated {
+			if err := r.hydrate(ctx, e); err != nil {
+				return err
+			}
+			hydrated = true
+		}
+		if err := rt.handler(ctx, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *EventRouter) hydrate(ctx context.Context, e *RoutedEvent) error {
+	var gid GID
+	switch {
+	case eventResourceType(e.Event) == "task" && e.Action != "deleted":
+		gid = e.Resource.GID
+	case eventResourceType(e.Event) == "story" && e.Parent.ResourceType == "task":
+		gid = e.Parent.GID
+	default:
+		return nil
+	}
+	task, err := r.client.GetTask(ctx, gid, r.TaskOptions)
+	if IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	e.Task = &task
+	return nil
+}
+
+// eventResourceType returns the resource type of e, falling back to the
+// legacy type field.
+func eventResourceType(e Event) string {
+	if e.Resource.ResourceType != "" {
+		return e.Resource.ResourceType
+	}
+	return e.Type
+}
+
+func (m EventMatch) matches(e Event) bool {
+	if m.ResourceType != "" && m.ResourceType != eventResourceType(e) {
+		return false
+	}
+	if m.ResourceSubtype != "" && m.ResourceSubtype != e.Resource.ResourceSubtype {
+		return false
+	}
+	if m.Action != "" && m.Action != e.Action {
+		return false
+	}
+	if m.ParentType != "" && m.ParentType != e.Parent.ResourceType {
+		return false
+	}
+	if len(m.Fields) == 0 {
+		return true
+	}
+	if e.Change == nil {
+		return false
+	}
+	for _, f := range m.Fields {
+		if f == e.Change.Field {
+			return true
+		}
+	}
+	return false
+}
+
+// DedupeEvents returns middleware dropping events identical to one of the
+// last size events handled successfully, as redelivered by webhook retries.
+func DedupeEvents(size int) EventMiddleware {
+	var mu sync.Mutex
+	seen := map[string]*list.Element{}
+	order := list.New()
+	return func(next EventHandler) EventHandler {
+		return func(ctx context.Context, e *RoutedEvent) error {
+			key := eventKey(e.Event)
+			mu.Lock()
+			_, ok := seen[key]
+			mu.Unlock()
+			if ok {
+				return nil
+			}
+			if err := next(ctx, e); err != nil {
+				return err
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if _, ok := seen[key]; ok {
+				return nil
+			}
+			seen[key] = order.PushBack(key)
+			if order.Len() > size {
+				oldest := order.Front()
+				order.Remove(oldest)
+				delete(seen, oldest.Value.(string))
+			}
+			return nil
+		}
+	}
+}
+
+func eventKey(e Event) string {
+	key := fmt.Sprintf("%s|%s|%s|%s|%s|%s", e.User.GID, eventResourceType(e), e.Resource.GID, e.Action, e.Parent.GID, e.CreatedAt.Format(time.RFC3339Nano))
+	if e.Change != nil {
+		key += "|" + e.Change.Field + "|" + e.Change.Action
+	}
+	return key
+}
+
+// LogEvents returns middleware logging every event and handler error to logger.
+func LogEvents(logger *log.Logger) EventMiddleware {
+	return func(next EventHandler) EventHandler {
+		return func(ctx context.Context, e *RoutedEvent) error {
+			err := next(ctx, e)
+			if err != nil {
+				logger.Printf("asana: event %s %s %s: %v", eventResourceType(e.Event), e.Resource.GID, e.Action, err)
+			} else {
+				logger.Printf("asana: event %s %s %s", eventResourceType(e.Event), e.Resource.GID, e.Action)
+			}
+			return err
+		}
+	}
+}
+
+// RecoverEvents returns middleware turning a panic of a handler into an error.
+func RecoverEvents() EventMiddleware {
+	return func(next EventHandler) EventHandler {
+		return func(ctx context.Context, e *RoutedEvent) (err error) {
+			defer func() {
+				if p := recover(); p != nil {
+					err = fmt.Errorf("asana: panic handling event %s %s %s: %v", eventResourceType(e.Event), e.Resource.GID, e.Action, p)
+				}
+			}()
+			return next(ctx, e)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package asana
+
+import (
+	"context"
+	"time"
+)
+
+const defaultWatchInterval = 10 * time.Second
+
+// GetEvents gets the events on a resource since syncToken was issued, and
+// the sync token to pass to the next call.
+//
+// An empty syncToken starts a sync: no events are returned, only the first
+// token. When syncToken is too old the new token is returned along with
+// ErrSyncTokenExpired; events in between were lost.
+//
+// https://asana.com/developers/api-reference/events
+func (c *Client) GetEvents(ctx context.Context, resourceGID GID, syncToken string) ([]Event, string, error) {
+	events := []Event{}
+	opt := &Filter{Resource: resourceGID, Sync: syncToken}
+	for {
+		var page []Event
+		res, err := c.call(ctx, "GET", "events", nil, nil, opt, &page)
+		if err != nil {
+			if IsPreconditionFailed(err) && res != nil && res.Sync != "" {
+				if syncToken == "" {
+					return events, res.Sync, nil
+				}
+				return nil, res.Sync, ErrSyncTokenExpired
+			}
+			return nil, syncToken, err
+		}
+		events = append(events, page...)
+		opt.Sync = res.Sync
+		if !res.HasMore {
+			return events, res.Sync, nil
+		}
+	}
+}
+
+// WatchOptions configures WatchEvents.
+type WatchOptions struct {
+	// SyncToken to resume from. An empty token watches events from now on.
+	SyncToken string
+	// Interval between two polls. Defaults to 10s.
+	Interval time.Duration
+	// MinBackoff and MaxBackoff bound the delay before polling again after an
+	// error, see RetryPolicy.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// OnSync is called with every new sync token, once the events it covers
+	// have been delivered.
+	OnSync func(syncToken string)
+	// OnError is called when a poll fails. ErrSyncTokenExpired means events
+	// were lost; watching goes on from the new token.
+	OnError func(err error)
+}
+
+// WatchEvents polls the events on a resource until ctx is done, delivering
+// them on the returned channel. The channel is closed when ctx is done.
+func (c *Client) WatchEvents(ctx context.Context, resourceGID GID, opt *WatchOptions) <-chan Event {
+	if opt == nil {
+		opt = &WatchOptions{}
+	}
+	interval := opt.Interval
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+	backoff := &RetryPolicy{MinBackoff: opt.MinBackoff, MaxBackoff: opt.MaxBackoff}
+
+	ch := make(chan Event)
+	go func() {
+		defer close(ch)
+		token := opt.SyncToken
+		failures := 0
+		for {
+			events, next, err := c.GetEvents(ctx, resourceGID, token)
+			if ctx.Err() != nil {
+				return
+			}
+			wait := interval
+			if err != nil && err != ErrSyncTokenExpired {
+				wait = backoff.backoff(failures)
+				failures++
+			} else {
+				failures = 0
+			}
+			if err != nil && opt.OnError != nil {
+				opt.OnError(err)
+			}
+			for _, e := range events {
+				select {
+				case ch <- e:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if next != token {
+				token = next
+				if opt.OnSync != nil {
+					opt.OnSync(token)
+				}
+			}
+			if sleep(ctx, wait) != nil {
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+// EventConsumer handles the events on a resource, resuming from the sync
+// token saved in Store. A token is saved only once Handle accepted the
+// events it covers, so events are delivered at least once across restarts.
+type EventConsumer struct {
+	Client   *Client
+	Store    SyncTokenStore
+	Resource GID
+
+	// Handle is called with every non-empty batch of events.
+	Handle func(ctx context.Context, events []Event) error
+	// OnExpired is called when the saved token has expired and events were
+	// missed, before the new token is saved. It typically re-reads the
+	// resource in full.
+	OnExpired func(ctx context.Context, resourceGID GID) error
+	// OnError is called by Run when a poll fails.
+	OnError func(err error)
+
+	// Interval, MinBackoff and MaxBackoff are used by Run, see WatchOptions.
+	Interval   time.Duration
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// Poll handles the events since the saved sync token once.
+func (ec *EventConsumer) Poll(ctx context.Context) error {
+	token, err := ec.Store.LoadSyncToken(ctx, ec.Resource)
+	if err != nil {
+		return err
+	}
+	events, next, err := ec.Client.GetEvents(ctx, ec.Resource, token)
+	if err == ErrSyncTokenExpired {
+		if ec.OnExpired != nil {
+			if err := ec.OnExpired(ctx, ec.Resource); err != nil {
+				return err
+			}
+		}
+		return ec.Store.SaveSyncToken(ctx, ec.Resource, next)
+	}
+	if err != nil {
+		return err
+	}
+	if len(events) > 0 && ec.Handle != nil {
+		if err := ec.Handle(ctx, events); err != nil {
+			return err
+		}
+	}
+	if next == token {
+		return nil
+	}
+	return ec.Store.SaveSyncToken(ctx, ec.Resource, next)
+}
+
+// Run polls until ctx is done, backing off after failed polls.
+// It returns the context error.
+func (ec *EventConsumer) Run(ctx context.Context) error {
+	interval := ec.Interval
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+	backoff := &RetryPolicy{MinBackoff: ec.MinBackoff, MaxBackoff: ec.MaxBackoff}
+	failures := 0
+	for {
+		wait := interval
+		if err := ec.Poll(ctx); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if ec.OnError != nil {
+				ec.OnError(err)
+			}
+			wait = backoff.backoff(failures)
+			failures++
+		} else {
+			failures = 0
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package asana
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+)
+
+// GID is the globally unique identifier of an Asana resource.
+//
+// Asana deprecated numeric ids in favour of string gids. For compatibility
+// a GID also decodes from a JSON number, and IntGID converts a legacy id.
+type GID string
+
+// IntGID converts a legacy numeric id to a GID.
+func IntGID(id int64) GID {
+	return GID(strconv.FormatInt(id, 10))
+}
+
+// Int64 converts the GID back to a legacy numeric id.
+// It fails for gids that do not fit in an int64.
+func (g GID) Int64() (int64, error) {
+	return strconv.ParseInt(string(g), 10, 64)
+}
+
+func (g GID) String() string {
+	return string(g)
+}
+
+// UnmarshalJSON decodes a GID from a JSON string or number.
+func (g *GID) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(b, []byte("null")) {
+		return nil
+	}
+	if len(b) > 0 && b[0] == '"' {
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		*g = GID(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(b, &n); err != nil {
+		return err
+	}
+	*g = GID(n.String())
+	return nil
+}
@@ -0,0 +1,167 @@
+package asana
+
+import (
+	"context"
+	"fmt"
+)
+
+// IterGoals returns an iterator over goals. opt must set one of Workspace,
+// Team, Portfolio or Project.
+//
+// https://asana.com/developers/api-reference/goals#query
+func (c *Client) IterGoals(opt *Filter) *GoalIterator {
+	return newIterator[Goal](c, "goals", opt)
+}
+
+// ListGoals gets goals. opt must set one of Workspace, Team, Portfolio or Project.
+//
+// https://asana.com/developers/api-reference/goals#query
+func (c *Client) ListGoals(ctx context.Context, opt *Filter) ([]Goal, error) {
+	return listAll(ctx, c.IterGoals(opt))
+}
+
+// GetGoal gets a goal.
+//
+// https://asana.com/developers/api-reference/goals#get-single
+func (c *Client) GetGoal(ctx context.Context, gid GID, opt *Filter) (Goal, error) {
+	goal := new(Goal)
+	err := c.Request(ctx, fmt.Sprintf("goals/%s", gid), opt, goal)
+	return *goal, err
+}
+
+// CreateGoal creates a goal. fields must include the workspace and name.
+//
+// https://asana.com/developers/api-reference/goals#create
+func (c *Client) CreateGoal(ctx context.Context, fields map[string]interface{}, opts *Filter) (Goal, error) {
+	goal := new(Goal)
+	_, err := c.request(ctx, "POST", "goals", fields, nil, opts, goal)
+	return *goal, err
+}
+
+// UpdateGoal updates a goal.
+//
+// https://asana.com/developers/api-reference/goals#update
+func (c *Client) UpdateGoal(ctx context.Context, gid GID, gu GoalUpdate, opt *Filter) (Goal, error) {
+	goal := new(Goal)
+	_, err := c.request(ctx, "PUT", fmt.Sprintf("goals/%s", gid), gu, nil, opt, goal)
+	return *goal, err
+}
+
+// DeleteGoal deletes a goal.
+//
+// https://asana.com/developers/api-reference/goals#delete
+func (c *Client) DeleteGoal(ctx context.Context, gid GID) error {
+	_, err := c.request(ctx, "DELETE", fmt.Sprintf("goals/%s", gid), nil, nil, nil, nil)
+	return err
+}
+
+// SetGoalMetric sets the metric of a goal. fields may include unit,
+// currency_code, precision, initial_number_value, target_number_value,
+// current_number_value and progress_source.
+//
+// https://asana.com/developers/api-reference/goals#metric
+func (c *Client) SetGoalMetric(ctx context.Context, goalGID GID, fields map[string]interface{}, opts *Filter) (Goal, error) {
+	goal := new(Goal)
+	_, err := c.request(ctx, "POST", fmt.Sprintf("goals/%s/setMetric", goalGID), fields, nil, opts, goal)
+	return *goal, err
+}
+
+// SetGoalMetricCurrentValue updates the current value of the metric of a goal.
+//
+// https://asana.com/developers/api-reference/goals#metric
+func (c *Client) SetGoalMetricCurrentValue(ctx context.Context, goalGID GID, value float64, opts *Filter) (Goal, error) {
+	goal := new(Goal)
+	_, err := c.request(ctx, "POST", fmt.Sprintf("goals/%s/setMetricCurrentValue", goalGID), map[string]interface{}{"current_number_value": value}, nil, opts, goal)
+	return *goal, err
+}
+
+// IterParentGoals returns an iterator over the goals a goal supports.
+//
+// https://asana.com/developers/api-reference/goals#parent-goals
+func (c *Client) IterParentGoals(goalGID GID, opt *Filter) *GoalIterator {
+	return newIterator[Goal](c, fmt.Sprintf("goals/%s/parentGoals", goalGID), opt)
+}
+
+// ListParentGoals gets the goals a goal supports.
+//
+// https://asana.com/developers/api-reference/goals#parent-goals
+func (c *Client) ListParentGoals(ctx context.Context, goalGID GID, opt *Filter) ([]Goal, error) {
+	return listAll(ctx, c.IterParentGoals(goalGID, opt))
+}
+
+// IterGoalRelationships returns an iterator over the resources supporting
+// the goal set as opt.SupportedGoal.
+//
+// https://asana.com/developers/api-reference/goal_relationships#query
+func (c *Client) IterGoalRelationships(opt *Filter) *GoalRelationshipIterator {
+	return newIterator[GoalRelationship](c, "goal_relationships", opt)
+}
+
+// ListGoalRelationships gets the resources supporting the goal set as
+// opt.SupportedGoal.
+//
+// https://asana.com/developers/api-reference/goal_relationships#query
+func (c *Client) ListGoalRelationships(ctx context.Context, opt *Filter) ([]GoalRelationship, error) {
+	return listAll(ctx, c.IterGoalRelationships(opt))
+}
+
+// ListSubgoals gets the goals supporting a goal.
+//
+// https://asana.com/developers/api-reference/goal_relationships#query
+func (c *Client) ListSubgoals(ctx context.Context, goalGID GID) ([]Resource, error) {
+	rels, err := c.ListGoalRelationships(ctx, &Filter{
+		SupportedGoal: goalGID,
+		OptFields:     []string{"resource_subtype", "supporting_resource.name"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	goals := []Resource{}
+	for _, rel := range rels {
+		if rel.ResourceSubtype == "subgoal" {
+			goals = append(goals, rel.SupportingResource)
+		}
+	}
+	return goals, nil
+}
+
+// AddSupportingRelationship makes a goal, project, portfolio or task
+// support a goal. A supporting goal becomes a subgoal.
+//
+// https://asana.com/developers/api-reference/goals#supporting-relationships
+func (c *Client) AddSupportingRelationship(ctx context.Context, goalGID GID, ru GoalRelationshipUpdate, opts *Filter) (GoalRelationship, error) {
+	rel := new(GoalRelationship)
+	_, err := c.request(ctx, "POST", fmt.Sprintf("goals/%s/addSupportingRelationship", goalGID), ru, nil, opts, rel)
+	return *rel, err
+}
+
+// RemoveSupportingRelationship makes a resource no longer support a goal.
+//
+// https://asana.com/developers/api-reference/goals#supporting-relationships
+func (c *Client) RemoveSupportingRelationship(ctx context.Context, goalGID GID, resourceGID GID, opts *Filter) error {
+	_, err := c.request(ctx, "POST", fmt.Sprintf("goals/%s/removeSupportingRelationship", goalGID), map[string]interface{}{"supporting_resource": resourceGID}, nil, opts, nil)
+	return err
+}
+
+// IterTimePeriods returns an iterator over the time periods of opt.Workspace.
+//
+// https://asana.com/developers/api-reference/time_periods#query
+func (c *Client) IterTimePeriods(opt *Filter) *TimePeriodIterator {
+	return newIterator[TimePeriod](c, "time_periods", opt)
+}
+
+// ListTimePeriods gets the time periods of opt.Workspace.
+//
+// https://asana.com/developers/api-reference/time_periods#query
+func (c *Client) ListTimePeriods(ctx context.Context, opt *Filter) ([]TimePeriod, error) {
+	return listAll(ctx, c.IterTimePeriods(opt))
+}
+
+// GetTimePeriod gets a time period.
+//
+// https://asana.com/developers/api-reference/time_periods#get-single
+func (c *Client) GetTimePeriod(ctx context.Context, gid GID, opt *Filter) (TimePeriod, error) {
+	period := new(TimePeriod)
+	err := c.Request(ctx, fmt.Sprintf("time_periods/%s", gid), opt, period)
+	return *period, err
+}
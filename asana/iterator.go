@@ -0,0 +1,138 @@
+package asana
+
+import (
+	"context"
+)
+
+// Iterator iterates over a paginated collection, fetching one page at a time.
+type Iterator[T any] struct {
+	c    *Client
+	path string
+	opt  Filter
+
+	page   []T    // items of the current page not returned yet
+	cur    T      // current item
+	offset string // offset the current page was requested with
+	next   string // offset of the following page
+	done   bool
+	err    error
+}
+
+type (
+	// WorkspaceIterator iterates over workspaces, fetching pages on demand.
+	WorkspaceIterator = Iterator[Workspace]
+	// UserIterator iterates over users, fetching pages on demand.
+	UserIterator = Iterator[User]
+	// ProjectIterator iterates over projects, fetching pages on demand.
+	ProjectIterator = Iterator[Project]
+	// TaskIterator iterates over tasks, fetching pages on demand.
+	TaskIterator = Iterator[Task]
+	// SectionIterator iterates over sections, fetching pages on demand.
+	SectionIterator = Iterator[Section]
+	// StoryIterator iterates over stories, fetching pages on demand.
+	StoryIterator = Iterator[Story]
+	// TagIterator iterates over tags, fetching pages on demand.
+	TagIterator = Iterator[Tag]
+	// WebhookIterator iterates over webhooks, fetching pages on demand.
+	WebhookIterator = Iterator[Webhook]
+	// CustomFieldIterator iterates over custom fields, fetching pages on demand.
+	CustomFieldIterator = Iterator[CustomField]
+	// CustomFieldSettingIterator iterates over custom field settings, fetching pages on demand.
+	CustomFieldSettingIterator = Iterator[CustomFieldSetting]
+	// AttachmentIterator iterates over attachments, fetching pages on demand.
+	AttachmentIterator = Iterator[Attachment]
+	// PortfolioIterator iterates over portfolios, fetching pages on demand.
+	PortfolioIterator = Iterator[Portfolio]
+	// PortfolioItemIterator iterates over the projects and portfolios of a
+	// portfolio, fetching pages on demand.
+	PortfolioItemIterator = Iterator[Resource]
+	// PortfolioMembershipIterator iterates over portfolio memberships, fetching pages on demand.
+	PortfolioMembershipIterator = Iterator[PortfolioMembership]
+	// GoalIterator iterates over goals, fetching pages on demand.
+	GoalIterator = Iterator[Goal]
+	// GoalRelationshipIterator iterates over goal relationships, fetching pages on demand.
+	GoalRelationshipIterator = Iterator[GoalRelationship]
+	// TimePeriodIterator iterates over time periods, fetching pages on demand.
+	TimePeriodIterator = Iterator[TimePeriod]
+	// StatusUpdateIterator iterates over status updates, fetching pages on demand.
+	StatusUpdateIterator = Iterator[StatusUpdate]
+	// TeamIterator iterates over teams, fetching pages on demand.
+	TeamIterator = Iterator[Team]
+	// TeamMembershipIterator iterates over team memberships, fetching pages on demand.
+	TeamMembershipIterator = Iterator[TeamMembership]
+)
+
+// newIterator returns an iterator over the collection at path.
+// A nil opt is the same as an empty Filter; opt.Offset sets the first page.
+func newIterator[T any](c *Client, path string, opt *Filter) *Iterator[T] {
+	it := &Iterator[T]{c: c, path: path}
+	if opt != nil {
+		it.opt = *opt
+		it.next = opt.Offset
+	}
+	return it
+}
+
+// Next advances to the next item, fetching the next page when the current
+// one is consumed. It returns false at the end of the collection or on
+// error, see Err.
+func (it *Iterator[T]) Next(ctx context.Context) bool {
+	for len(it.page) == 0 {
+		if it.done || it.err != nil {
+			return false
+		}
+		var page []T
+		opt := it.opt
+		opt.Offset = it.next
+		next, err := it.c.request(ctx, "GET", it.path, nil, nil, &opt, &page)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.page = page
+		it.offset = it.next
+		if next == nil || next.Offset == "" {
+			it.next, it.done = "", true
+		} else {
+			it.next = next.Offset
+		}
+	}
+	it.cur, it.page = it.page[0], it.page[1:]
+	return true
+}
+
+// Value returns the current item.
+func (it *Iterator[T]) Value() T {
+	return it.cur
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
+
+// Offset returns the offset the page holding the current item was requested
+// with. Passing it as Filter.Offset resumes the iteration at that page, so at
+// most one page of items is seen twice.
+func (it *Iterator[T]) Offset() string {
+	return it.offset
+}
+
+// NextOffset returns the NextPage.Offset of the current page, or "" if it is
+// the last one. Passing it as Filter.Offset resumes the iteration after the
+// current page.
+func (it *Iterator[T]) NextOffset() string {
+	return it.next
+}
+
+// listAll drains it into a slice.
+func listAll[T any](ctx context.Context, it *Iterator[T]) ([]T, error) {
+	rets := []T{}
+	for it.Next(ctx) {
+		rets = append(rets, it.Value())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return rets, nil
+}
@@ -0,0 +1,175 @@
+package asana
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// IterPortfolios returns an iterator over the portfolios of opt.Owner in
+// opt.Workspace. Both are required.
+//
+// https://asana.com/developers/api-reference/portfolios#query
+func (c *Client) IterPortfolios(opt *Filter) *PortfolioIterator {
+	return newIterator[Portfolio](c, "portfolios", opt)
+}
+
+// ListPortfolios gets the portfolios of opt.Owner in opt.Workspace.
+//
+// https://asana.com/developers/api-reference/portfolios#query
+func (c *Client) ListPortfolios(ctx context.Context, opt *Filter) ([]Portfolio, error) {
+	return listAll(ctx, c.IterPortfolios(opt))
+}
+
+// GetPortfolio gets a portfolio.
+//
+// https://asana.com/developers/api-reference/portfolios#get-single
+func (c *Client) GetPortfolio(ctx context.Context, gid GID, opt *Filter) (Portfolio, error) {
+	portfolio := new(Portfolio)
+	err := c.Request(ctx, fmt.Sprintf("portfolios/%s", gid), opt, portfolio)
+	return *portfolio, err
+}
+
+// CreatePortfolio creates a portfolio. fields must include the workspace and name.
+//
+// https://asana.com/developers/api-reference/portfolios#create
+func (c *Client) CreatePortfolio(ctx context.Context, fields map[string]interface{}, opts *Filter) (Portfolio, error) {
+	portfolio := new(Portfolio)
+	_, err := c.request(ctx, "POST", "portfolios", fields, nil, opts, portfolio)
+	return *portfolio, err
+}
+
+// UpdatePortfolio updates a portfolio.
+//
+// https://asana.com/developers/api-reference/portfolios#update
+func (c *Client) UpdatePortfolio(ctx context.Context, gid GID, pu PortfolioUpdate, opt *Filter) (Portfolio, error) {
+	portfolio := new(Portfolio)
+	_, err := c.request(ctx, "PUT", fmt.Sprintf("portfolios/%s", gid), pu, nil, opt, portfolio)
+	return *portfolio, err
+}
+
+// DeletePortfolio deletes a portfolio.
+//
+// https://asana.com/developers/api-reference/portfolios#delete
+func (c *Client) DeletePortfolio(ctx context.Context, gid GID) error {
+	_, err := c.request(ctx, "DELETE", fmt.Sprintf("portfolios/%s", gid), nil, nil, nil, nil)
+	return err
+}
+
+// IterPortfolioItems returns an iterator over the projects and portfolios
+// of a portfolio. Their ResourceType tells them apart.
+//
+// https://asana.com/developers/api-reference/portfolios#items
+func (c *Client) IterPortfolioItems(portfolioGID GID, opt *Filter) *PortfolioItemIterator {
+	return newIterator[Resource](c, fmt.Sprintf("portfolios/%s/items", portfolioGID), opt)
+}
+
+// ListPortfolioItems gets the projects and portfolios of a portfolio.
+//
+// https://asana.com/developers/api-reference/portfolios#items
+func (c *Client) ListPortfolioItems(ctx context.Context, portfolioGID GID, opt *Filter) ([]Resource, error) {
+	return listAll(ctx, c.IterPortfolioItems(portfolioGID, opt))
+}
+
+// AddPortfolioItem adds a project or portfolio to a portfolio.
+//
+// https://asana.com/developers/api-reference/portfolios#items
+func (c *Client) AddPortfolioItem(ctx context.Context, portfolioGID GID, iu PortfolioItemUpdate, opts *Filter) error {
+	_, err := c.request(ctx, "POST", fmt.Sprintf("portfolios/%s/addItem", portfolioGID), iu, nil, opts, nil)
+	return err
+}
+
+// RemovePortfolioItem removes a project or portfolio from a portfolio.
+//
+// https://asana.com/developers/api-reference/portfolios#items
+func (c *Client) RemovePortfolioItem(ctx context.Context, portfolioGID GID, itemGID GID, opts *Filter) error {
+	_, err := c.request(ctx, "POST", fmt.Sprintf("portfolios/%s/removeItem", portfolioGID), map[string]interface{}{"item": itemGID}, nil, opts, nil)
+	return err
+}
+
+// AddPortfolioMembers gives users access to a portfolio.
+//
+// https://asana.com/developers/api-reference/portfolios#members
+func (c *Client) AddPortfolioMembers(ctx context.Context, portfolioGID GID, userGIDs []GID, opts *Filter) (Portfolio, error) {
+	portfolio := new(Portfolio)
+	_, err := c.request(ctx, "POST", fmt.Sprintf("portfolios/%s/addMembers", portfolioGID), map[string]interface{}{"members": joinGIDs(userGIDs)}, nil, opts, portfolio)
+	return *portfolio, err
+}
+
+// RemovePortfolioMembers removes the access of users to a portfolio.
+//
+// https://asana.com/developers/api-reference/portfolios#members
+func (c *Client) RemovePortfolioMembers(ctx context.Context, portfolioGID GID, userGIDs []GID, opts *Filter) (Portfolio, error) {
+	portfolio := new(Portfolio)
+	_, err := c.request(ctx, "POST", fmt.Sprintf("portfolios/%s/removeMembers", portfolioGID), map[string]interface{}{"members": joinGIDs(userGIDs)}, nil, opts, portfolio)
+	return *portfolio, err
+}
+
+// IterPortfolioMemberships returns an iterator over the memberships of a portfolio.
+//
+// https://asana.com/developers/api-reference/portfolio_memberships#query
+func (c *Client) IterPortfolioMemberships(portfolioGID GID, opt *Filter) *PortfolioMembershipIterator {
+	return newIterator[PortfolioMembership](c, fmt.Sprintf("portfolios/%s/portfolio_memberships", portfolioGID), opt)
+}
+
+// ListPortfolioMemberships gets the memberships of a portfolio.
+//
+// https://asana.com/developers/api-reference/portfolio_memberships#query
+func (c *Client) ListPortfolioMemberships(ctx context.Context, portfolioGID GID, opt *Filter) ([]PortfolioMembership, error) {
+	return listAll(ctx, c.IterPortfolioMemberships(portfolioGID, opt))
+}
+
+// WalkPortfolio calls fn for every item of a portfolio and, recursively, of
+// the portfolios it contains, with the portfolio the item was found in.
+// Items are visited depth first; a portfolio contained several times, or
+// in itself, is only walked once. It stops at the first error, which is
+// returned.
+func (c *Client) WalkPortfolio(ctx context.Context, portfolioGID GID, fn func(item Resource, parentGID GID) error) error {
+	walked := map[GID]bool{}
+	var walk func(gid GID) error
+	walk = func(gid GID) error {
+		walked[gid] = true
+		items, err := c.ListPortfolioItems(ctx, gid, &Filter{OptFields: []string{"name", "resource_type"}})
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			if err := fn(item, gid); err != nil {
+				return err
+			}
+			if item.ResourceType == "portfolio" && !walked[item.GID] {
+				if err := walk(item.GID); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	return walk(portfolioGID)
+}
+
+// ListPortfolioProjects gets the projects of a portfolio and of the
+// portfolios it contains, recursively. Each project is listed once.
+func (c *Client) ListPortfolioProjects(ctx context.Context, portfolioGID GID) ([]Project, error) {
+	projects := []Project{}
+	seen := map[GID]bool{}
+	err := c.WalkPortfolio(ctx, portfolioGID, func(item Resource, parentGID GID) error {
+		if item.ResourceType == "project" && !seen[item.GID] {
+			seen[item.GID] = true
+			projects = append(projects, Project{GID: item.GID, Name: item.Name})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
+func joinGIDs(gids []GID) string {
+	s := make([]string, len(gids))
+	for i, gid := range gids {
+		s[i] = string(gid)
+	}
+	return strings.Join(s, ",")
+}
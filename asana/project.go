@@ -0,0 +1,111 @@
+package asana
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// GetProject gets a project.
+//
+// https://asana.com/developers/api-reference/projects#get-single
+func (c *Client) GetProject(ctx context.Context, gid GID, opt *Filter) (Project, error) {
+	project := new(Project)
+	err := c.Request(ctx, fmt.Sprintf("projects/%s", gid), opt, project)
+	return *project, err
+}
+
+// CreateProject creates a project. fields must include the workspace, or
+// the team in an organization.
+//
+// https://asana.com/developers/api-reference/projects#create
+func (c *Client) CreateProject(ctx context.Context, fields map[string]interface{}, opts *Filter) (Project, error) {
+	project := new(Project)
+	_, err := c.request(ctx, "POST", "projects", fields, nil, opts, project)
+	return *project, err
+}
+
+// UpdateProject updates a project.
+//
+// https://asana.com/developers/api-reference/projects#update
+func (c *Client) UpdateProject(ctx context.Context, gid GID, pu ProjectUpdate, opt *Filter) (Project, error) {
+	project := new(Project)
+	_, err := c.request(ctx, "PUT", fmt.Sprintf("projects/%s", gid), pu, nil, opt, project)
+	return *project, err
+}
+
+// DeleteProject deletes a project.
+//
+// https://asana.com/developers/api-reference/projects#delete
+func (c *Client) DeleteProject(ctx context.Context, gid GID) error {
+	_, err := c.request(ctx, "DELETE", fmt.Sprintf("projects/%s", gid), nil, nil, nil, nil)
+	return err
+}
+
+// DuplicateProject starts copying a project. The copy is made by the
+// returned job, see WaitForJob.
+//
+// https://asana.com/developers/api-reference/projects#duplicate
+func (c *Client) DuplicateProject(ctx context.Context, gid GID, pd ProjectDuplicate, opts *Filter) (Job, error) {
+	job := new(Job)
+	_, err := c.request(ctx, "POST", fmt.Sprintf("projects/%s/duplicate", gid), pd, nil, opts, job)
+	return *job, err
+}
+
+// GetJob gets a job.
+//
+// https://asana.com/developers/api-reference/jobs#get-single
+func (c *Client) GetJob(ctx context.Context, gid GID, opt *Filter) (Job, error) {
+	job := new(Job)
+	err := c.Request(ctx, fmt.Sprintf("jobs/%s", gid), opt, job)
+	return *job, err
+}
+
+// JobWaitOptions controls how WaitForJob polls a job.
+type JobWaitOptions struct {
+	// MinBackoff and MaxBackoff bound the delay between two polls, which
+	// grows exponentially, see RetryPolicy.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// MaxWait bounds the whole wait. Zero waits as long as the job runs,
+	// unless ctx has a deadline.
+	MaxWait time.Duration
+}
+
+// WaitForJob polls a job while it is not started or in progress, and
+// returns the project it created once it succeeded. The project is zero for
+// jobs that do not create one. It returns an error wrapping ErrJobFailed if
+// the job failed, an error for any other status, and the first error of a
+// poll or of ctx otherwise.
+func (c *Client) WaitForJob(ctx context.Context, gid GID, opt *JobWaitOptions) (Project, error) {
+	if opt == nil {
+		opt = &JobWaitOptions{}
+	}
+	if opt.MaxWait > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opt.MaxWait)
+		defer cancel()
+	}
+	backoff := &RetryPolicy{MinBackoff: opt.MinBackoff, MaxBackoff: opt.MaxBackoff}
+	for attempt := 0; ; attempt++ {
+		job, err := c.GetJob(ctx, gid, nil)
+		if err != nil {
+			return Project{}, err
+		}
+		switch job.Status {
+		case "not_started", "in_progress":
+		case "succeeded":
+			if job.NewProject == nil {
+				return Project{}, nil
+			}
+			return *job.NewProject, nil
+		case "failed":
+			return Project{}, fmt.Errorf("%w: %s", ErrJobFailed, gid)
+		default:
+			return Project{}, fmt.Errorf("asana: job %s has unexpected status %q", gid, job.Status)
+		}
+		if err := sleep(ctx, backoff.backoff(attempt)); err != nil {
+			return Project{}, err
+		}
+	}
+}
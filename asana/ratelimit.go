@@ -0,0 +1,218 @@
+package asana
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// ClientOption configures a Client created by NewClient.
+type ClientOption func(*Client)
+
+// WithRetryPolicy sets the policy used to re-send failed requests.
+func WithRetryPolicy(p *RetryPolicy) ClientOption {
+	return func(c *Client) {
+		c.Retry = p
+	}
+}
+
+// WithRateLimit limits the client to rps requests per second on average,
+// allowing bursts of up to burst requests. The limit is shared by every
+// goroutine using the client.
+func WithRateLimit(rps float64, burst int) ClientOption {
+	return func(c *Client) {
+		if burst < 1 {
+			burst = 1
+		}
+		c.limiter.rate = rps
+		c.limiter.burst = float64(burst)
+		c.limiter.tokens = float64(burst)
+	}
+}
+
+// WithMaxInFlight limits the number of requests the client has in flight at
+// once. A request stays in flight until its response body is closed.
+func WithMaxInFlight(n int) ClientOption {
+	return func(c *Client) {
+		if n > 0 {
+			c.limiter.sem = make(chan struct{}, n)
+		} else {
+			c.limiter.sem = nil
+		}
+	}
+}
+
+// LimiterState is a snapshot of the client-side limits and of how often
+// requests were throttled locally by them or remotely by Asana.
+type LimiterState struct {
+	// Rate is the configured number of requests per second, 0 if unlimited.
+	Rate float64
+	// Burst is the configured bucket size.
+	Burst int
+	// Tokens currently available. It is negative when requests are queued.
+	Tokens float64
+	// MaxInFlight is the configured concurrency limit, 0 if unlimited.
+	MaxInFlight int
+	// InFlight is the number of requests being sent or read.
+	InFlight int
+	// Waiting is the number of requests currently blocked on a local limit.
+	Waiting int
+	// LocalThrottled counts requests that had to wait on a local limit.
+	LocalThrottled uint64
+	// LocalWait is the total time requests spent waiting on local limits.
+	LocalWait time.Duration
+	// RemoteThrottled counts 429 Too Many Requests responses from Asana.
+	RemoteThrottled uint64
+	// LastRemoteThrottle is when the last 429 response was received.
+	LastRemoteThrottle time.Time
+}
+
+// LimiterState returns the current state of the client-side limits.
+func (c *Client) LimiterState() LimiterState {
+	l := c.limiter
+	if l == nil {
+		return LimiterState{}
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.rate > 0 {
+		l.refill(time.Now())
+	}
+	return LimiterState{
+		Rate:               l.rate,
+		Burst:              int(l.burst),
+		Tokens:             l.tokens,
+		MaxInFlight:        cap(l.sem),
+		InFlight:           len(l.sem),
+		Waiting:            l.waiting,
+		LocalThrottled:     l.localThrottled,
+		LocalWait:          l.localWait,
+		RemoteThrottled:    l.remoteThrottled,
+		LastRemoteThrottle: l.lastRemote,
+	}
+}
+
+// limiter is a token bucket combined with a semaphore of in-flight requests.
+// The zero value does not limit anything.
+type limiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	sem    chan struct{}
+
+	waiting         int
+	localThrottled  uint64
+	localWait       time.Duration
+	remoteThrottled uint64
+	lastRemote      time.Time
+}
+
+func (l *limiter) refill(now time.Time) {
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+}
+
+// wait takes a token from the bucket, blocking until one is available.
+func (l *limiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	if l.rate <= 0 {
+		l.mu.Unlock()
+		return nil
+	}
+	l.refill(time.Now())
+	l.tokens--
+	if l.tokens >= 0 {
+		l.mu.Unlock()
+		return nil
+	}
+	d := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	l.waiting++
+	l.localThrottled++
+	l.mu.Unlock()
+
+	start := time.Now()
+	err := sleep(ctx, d)
+
+	l.mu.Lock()
+	l.waiting--
+	l.localWait += time.Since(start)
+	if err != nil {
+		// Give the reserved token back.
+		l.tokens++
+	}
+	l.mu.Unlock()
+	return err
+}
+
+// acquire takes an in-flight slot, blocking until one is free.
+func (l *limiter) acquire(ctx context.Context) error {
+	if l == nil || l.sem == nil {
+		return nil
+	}
+	select {
+	case l.sem <- struct{}{}:
+		return nil
+	default:
+	}
+
+	l.mu.Lock()
+	l.waiting++
+	l.localThrottled++
+	l.mu.Unlock()
+
+	start := time.Now()
+	var err error
+	select {
+	case l.sem <- struct{}{}:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	l.mu.Lock()
+	l.waiting--
+	l.localWait += time.Since(start)
+	l.mu.Unlock()
+	return err
+}
+
+func (l *limiter) release() {
+	if l == nil || l.sem == nil {
+		return
+	}
+	<-l.sem
+}
+
+// throttled records a 429 response.
+func (l *limiter) throttled() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	l.remoteThrottled++
+	l.lastRemote = time.Now()
+	l.mu.Unlock()
+}
+
+// releaseBody releases an in-flight slot when the response body is closed.
+type releaseBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
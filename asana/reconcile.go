@@ -0,0 +1,279 @@
+package asana
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// WebhookOp is the kind of change a WebhookReconciler makes.
+type WebhookOp string
+
+const (
+	WebhookOpCreate   WebhookOp = "create"
+	WebhookOpRecreate WebhookOp = "recreate"
+	WebhookOpDelete   WebhookOp = "delete"
+)
+
+type (
+	// WebhookSpec describes a webhook that should exist.
+	// A webhook is identified by its resource and target.
+	WebhookSpec struct {
+		Resource GID
+		Target   string
+		Filters  []WebhookFilter
+	}
+
+	// WebhookChange is a change of a WebhookPlan.
+	WebhookChange struct {
+		Op     WebhookOp
+		Reason string
+		// Spec is the webhook to create, for create and recreate changes.
+		Spec WebhookSpec
+		// Existing is the webhook to delete, for recreate and delete changes.
+		Existing Webhook
+
+		// Applied and Err report the outcome of WebhookReconciler.Apply.
+		// Created is the webhook created by the change.
+		Applied bool
+		Created Webhook
+		Err     error
+	}
+
+	// WebhookPlan lists the changes converging existing webhooks to the desired ones.
+	WebhookPlan struct {
+		Changes   []*WebhookChange
+		Unchanged []Webhook
+		DryRun    bool
+	}
+
+	// WebhookReconciler converges the webhooks of a workspace to a desired set.
+	//
+	// Existing webhooks are matched to the desired ones by resource and target.
+	// A matching webhook that is inactive or has other filters is recreated,
+	// duplicates are deleted, and webhooks that are not desired are deleted.
+	WebhookReconciler struct {
+		Client    *Client
+		Workspace GID
+		// Owns selects the existing webhooks the reconciler manages; others
+		// are never deleted. Defaults to the webhooks whose target is the
+		// target of a desired webhook, so webhooks of other integrations are
+		// left alone; set it to delete webhooks on targets no longer desired.
+		Owns func(Webhook) bool
+		// Secrets is the store of the WebhookHandler receiving the webhooks.
+		// When set, the secret of a target is forgotten before a webhook is
+		// created on it, so that the handler accepts the new handshake, and
+		// every desired webhook must have its own target.
+		Secrets WebhookSecretStore
+		// SecretKey returns the key of the secret of target in Secrets.
+		// Defaults to the path of target, as WebhookHandler.Key does.
+		SecretKey func(target string) string
+		// DryRun makes Reconcile only plan the changes.
+		DryRun bool
+	}
+)
+
+// Reconcile plans the changes converging the webhooks to desired and, unless
+// DryRun is set, applies them. The plan reports the outcome of every change.
+func (r *WebhookReconciler) Reconcile(ctx context.Context, desired []WebhookSpec) (*WebhookPlan, error) {
+	plan, err := r.Plan(ctx, desired)
+	if err != nil || r.DryRun {
+		return plan, err
+	}
+	return plan, r.Apply(ctx, plan)
+}
+
+// Plan lists the changes converging the webhooks to desired without making them.
+func (r *WebhookReconciler) Plan(ctx context.Context, desired []WebhookSpec) (*WebhookPlan, error) {
+	existing, err := r.Client.ListWebhooks(ctx, &Filter{
+		Workspace: r.Workspace,
+		OptFields: []string{"resource", "resource.name", "target", "active", "filters"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	owns := r.Owns
+	if owns == nil {
+		targets := map[string]bool{}
+		for _, spec := range desired {
+			targets[spec.Target] = true
+		}
+		owns = func(w Webhook) bool { return targets[w.Target] }
+	}
+
+	byKey := map[string][]Webhook{}
+	var keys []string
+	for _, w := range existing {
+		if !owns(w) {
+			continue
+		}
+		key := webhookKey(w.Resource.GID, w.Target)
+		if _, ok := byKey[key]; !ok {
+			keys = append(keys, key)
+		}
+		byKey[key] = append(byKey[key], w)
+	}
+
+	plan := &WebhookPlan{DryRun: r.DryRun}
+	seen := map[string]bool{}
+	targets := map[string]GID{}
+	for _, spec := range desired {
+		key := webhookKey(spec.Resource, spec.Target)
+		if seen[key] {
+			return nil, fmt.Errorf("asana: webhook for resource %s and target %s is desired twice", spec.Resource, spec.Target)
+		}
+		seen[key] = true
+		if other, ok := targets[spec.Target]; ok && r.Secrets != nil {
+			// Secrets are stored by target: the webhooks would overwrite
+			// each other's secret.
+			return nil, fmt.Errorf("asana: webhooks for resources %s and %s share target %s", other, spec.Resource, spec.Target)
+		}
+		targets[spec.Target] = spec.Resource
+
+		matches := byKey[key]
+		delete(byKey, key)
+		keep := -1
+		for i, w := range matches {
+			if w.Active && sameWebhookFilters(w.Filters, spec.Filters) {
+				keep = i
+				break
+			}
+		}
+		switch {
+		case keep >= 0:
+			plan.Unchanged = append(plan.Unchanged, matches[keep])
+		case len(matches) > 0:
+			reason := "filters changed"
+			if !matches[0].Active {
+				reason = "inactive"
+			}
+			plan.Changes = append(plan.Changes, &WebhookChange{Op: WebhookOpRecreate, Reason: reason, Spec: spec, Existing: matches[0]})
+			keep = 0
+		default:
+			plan.Changes = append(plan.Changes, &WebhookChange{Op: WebhookOpCreate, Reason: "missing", Spec: spec})
+		}
+		for i, w := range matches {
+			if i != keep {
+				plan.Changes = append(plan.Changes, &WebhookChange{Op: WebhookOpDelete, Reason: "duplicate", Existing: w})
+			}
+		}
+	}
+	for _, key := range keys {
+		for _, w := range byKey[key] {
+			plan.Changes = append(plan.Changes, &WebhookChange{Op: WebhookOpDelete, Reason: "not desired", Existing: w})
+		}
+	}
+	return plan, nil
+}
+
+// Apply makes the changes of plan. A recreated webhook is deleted before the
+// new one is created, since both would share the secret of their target:
+// events occurring in between are missed. Apply goes on after a failed
+// change and returns an error if any failed; see WebhookChange.Err.
+func (r *WebhookReconciler) Apply(ctx context.Context, plan *WebhookPlan) error {
+	failed := 0
+	for _, ch := range plan.Changes {
+		if ch.Op == WebhookOpRecreate || ch.Op == WebhookOpDelete {
+			ch.Err = r.Client.DeleteWebhook(ctx, ch.Existing.GID)
+		}
+		if ch.Err == nil && (ch.Op == WebhookOpCreate || ch.Op == WebhookOpRecreate) {
+			ch.Err = r.forgetSecret(ctx, ch.Spec.Target)
+			if ch.Err == nil {
+				ch.Created, ch.Err = r.Client.CreateWebhook(ctx, ch.Spec.Resource, ch.Spec.Target, ch.Spec.Filters...)
+			}
+		}
+		if ch.Err != nil {
+			failed++
+			continue
+		}
+		ch.Applied = true
+	}
+	if failed > 0 {
+		return fmt.Errorf("asana: %d of %d webhook changes failed", failed, len(plan.Changes))
+	}
+	return nil
+}
+
+func (r *WebhookReconciler) forgetSecret(ctx context.Context, target string) error {
+	if r.Secrets == nil {
+		return nil
+	}
+	key := target
+	if r.SecretKey != nil {
+		key = r.SecretKey(target)
+	} else if u, err := url.Parse(target); err == nil {
+		key = u.Path
+	}
+	return r.Secrets.SaveWebhookSecret(ctx, key, "")
+}
+
+// String formats the plan as a report, one line per change.
+func (p *WebhookPlan) String() string {
+	var b strings.Builder
+	counts := map[WebhookOp]int{}
+	for _, ch := range p.Changes {
+		counts[ch.Op]++
+		fmt.Fprintf(&b, "%-8s ", ch.Op)
+		switch ch.Op {
+		case WebhookOpCreate:
+			fmt.Fprintf(&b, "resource=%s target=%s filters=%s", ch.Spec.Resource, ch.Spec.Target, formatWebhookFilters(ch.Spec.Filters))
+		case WebhookOpRecreate:
+			fmt.Fprintf(&b, "webhook=%s resource=%s target=%s filters=%s", ch.Existing.GID, ch.Spec.Resource, ch.Spec.Target, formatWebhookFilters(ch.Spec.Filters))
+		case WebhookOpDelete:
+			fmt.Fprintf(&b, "webhook=%s resource=%s target=%s", ch.Existing.GID, ch.Existing.Resource.GID, ch.Existing.Target)
+		}
+		fmt.Fprintf(&b, " (%s)", ch.Reason)
+		switch {
+		case ch.Err != nil:
+			fmt.Fprintf(&b, ": failed: %v", ch.Err)
+		case ch.Applied:
+			b.WriteString(": done")
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "%d to create, %d to recreate, %d to delete, %d unchanged",
+		counts[WebhookOpCreate], counts[WebhookOpRecreate], counts[WebhookOpDelete], len(p.Unchanged))
+	if p.DryRun {
+		b.WriteString(" (dry run)")
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+func webhookKey(resourceGID GID, target string) string {
+	return string(resourceGID) + " " + target
+}
+
+// sameWebhookFilters compares filters regardless of their order.
+func sameWebhookFilters(a, b []WebhookFilter) bool {
+	return formatWebhookFilters(a) == formatWebhookFilters(b)
+}
+
+// formatWebhookFilters formats filters in a canonical order,
+// e.g. "story.added,task.changed[due_on,name]".
+func formatWebhookFilters(filters []WebhookFilter) string {
+	if len(filters) == 0 {
+		return "none"
+	}
+	ss := make([]string, 0, len(filters))
+	for _, f := range filters {
+		s := f.ResourceType
+		if f.ResourceSubtype != "" {
+			s += "/" + f.ResourceSubtype
+		}
+		if f.Action != "" {
+			s += "." + f.Action
+		}
+		if len(f.Fields) > 0 {
+			fields := append([]string(nil), f.Fields...)
+			sort.Strings(fields)
+			s += "[" + strings.Join(fields, ",") + "]"
+		}
+		ss = append(ss, s)
+	}
+	sort.Strings(ss)
+	return strings.Join(ss, ",")
+}
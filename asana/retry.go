@@ -0,0 +1,130 @@
+package asana
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultMinBackoff = 500 * time.Millisecond
+	defaultMaxBackoff = 30 * time.Second
+)
+
+// RetryPolicy controls how Client retries requests that failed with a
+// transport error, 429 Too Many Requests or a 5xx status code.
+//
+// A nil policy on Client disables retries.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	// Values lower than 2 disable retries.
+	MaxAttempts int
+	// MinBackoff is the base delay of the exponential backoff.
+	// Defaults to 500ms.
+	MinBackoff time.Duration
+	// MaxBackoff caps a single delay. A request whose response asks, with
+	// a Retry-After header, for a longer delay is not retried. Defaults to 30s.
+	MaxBackoff time.Duration
+	// RetryNonIdempotent allows POST and PUT requests to be re-sent.
+	// Only enable it when duplicated writes are acceptable.
+	RetryNonIdempotent bool
+}
+
+// DefaultRetryPolicy returns a policy suitable for most API consumers:
+// up to 5 attempts of idempotent requests.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 5,
+		MinBackoff:  defaultMinBackoff,
+		MaxBackoff:  defaultMaxBackoff,
+	}
+}
+
+// canRetry reports whether a request using method may be re-sent.
+func (p *RetryPolicy) canRetry(method string) bool {
+	if p == nil || p.MaxAttempts < 2 {
+		return false
+	}
+	switch method {
+	case "GET", "HEAD", "OPTIONS", "DELETE":
+		return true
+	}
+	return p.RetryNonIdempotent
+}
+
+// backoff returns the delay before the attempt following attempt (0-based).
+// It grows exponentially from MinBackoff and is jittered to [d/2, d).
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	min, max := p.MinBackoff, p.MaxBackoff
+	if min <= 0 {
+		min = defaultMinBackoff
+	}
+	if max <= 0 {
+		max = defaultMaxBackoff
+	}
+	d := min
+	for i := 0; i < attempt && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	half := int64(d / 2)
+	return time.Duration(half + rand.Int63n(half+1))
+}
+
+// delay returns how long to wait before retrying after resp. A Retry-After
+// header is the minimum wait: when it exceeds MaxBackoff, delay reports
+// false and the request should not be retried.
+func (p *RetryPolicy) delay(attempt int, resp *http.Response) (time.Duration, bool) {
+	if resp != nil {
+		if d, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+			max := p.MaxBackoff
+			if max <= 0 {
+				max = defaultMaxBackoff
+			}
+			return d, d <= max
+		}
+	}
+	return p.backoff(attempt), true
+}
+
+// retryable reports whether a response with status code should be retried.
+func retryable(code int) bool {
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
+
+// retryAfter parses a Retry-After header, given either in seconds or as an HTTP date.
+func retryAfter(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			secs = 0
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,237 @@
+package asana
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/go-querystring/query"
+)
+
+const maxSearchLimit = 100
+
+type (
+	// TaskSearch is a query of SearchTasks. Zero fields match any task.
+	// Dates are "YYYY-MM-DD" strings.
+	TaskSearch struct {
+		// Text matches the name and description of tasks.
+		Text string `url:"text,omitempty"`
+		// ResourceSubtype is "default_task", "milestone" or "approval".
+		ResourceSubtype string `url:"resource_subtype,omitempty"`
+
+		AssigneeAny []GID `url:"assignee.any,comma,omitempty"`
+		AssigneeNot []GID `url:"assignee.not,comma,omitempty"`
+		ProjectsAny []GID `url:"projects.any,comma,omitempty"`
+		ProjectsNot []GID `url:"projects.not,comma,omitempty"`
+		ProjectsAll []GID `url:"projects.all,comma,omitempty"`
+		SectionsAny []GID `url:"sections.any,comma,omitempty"`
+		SectionsNot []GID `url:"sections.not,comma,omitempty"`
+		SectionsAll []GID `url:"sections.all,comma,omitempty"`
+		TagsAny     []GID `url:"tags.any,comma,omitempty"`
+		TagsNot     []GID `url:"tags.not,comma,omitempty"`
+		TagsAll     []GID `url:"tags.all,comma,omitempty"`
+
+		DueOn         string `url:"due_on,omitempty"`
+		DueOnBefore   string `url:"due_on.before,omitempty"`
+		DueOnAfter    string `url:"due_on.after,omitempty"`
+		StartOn       string `url:"start_on,omitempty"`
+		StartOnBefore string `url:"start_on.before,omitempty"`
+		StartOnAfter  string `url:"start_on.after,omitempty"`
+
+		CreatedAtBefore   time.Time `url:"created_at.before,omitempty"`
+		CreatedAtAfter    time.Time `url:"created_at.after,omitempty"`
+		ModifiedAtBefore  time.Time `url:"modified_at.before,omitempty"`
+		ModifiedAtAfter   time.Time `url:"modified_at.after,omitempty"`
+		CompletedAtBefore time.Time `url:"completed_at.before,omitempty"`
+		CompletedAtAfter  time.Time `url:"completed_at.after,omitempty"`
+
+		Completed     *bool `url:"completed,omitempty"`
+		IsSubtask     *bool `url:"is_subtask,omitempty"`
+		HasAttachment *bool `url:"has_attachment,omitempty"`
+		IsBlocked     *bool `url:"is_blocked,omitempty"`
+
+		// CustomFields restricts the values of custom fields.
+		CustomFields []CustomFieldPredicate `url:"-"`
+
+		// OptFields are the task fields to return.
+		OptFields []string `url:"-"`
+		// Limit is the maximum number of tasks to return, 0 for all.
+		Limit int `url:"-"`
+	}
+
+	// CustomFieldPredicate restricts the value of a custom field in a
+	// TaskSearch. Use the constructors matching the type of the field.
+	CustomFieldPredicate struct {
+		Field GID
+		// Op is "value", "is_set", "contains", "starts_with", "ends_with",
+		// "less_than", "greater_than", "before", "after", "any" or "all".
+		Op    string
+		Value string
+	}
+)
+
+// CustomFieldIsSet matches tasks where the field is set, or unset.
+func CustomFieldIsSet(field GID, set bool) CustomFieldPredicate {
+	return CustomFieldPredicate{Field: field, Op: "is_set", Value: strconv.FormatBool(set)}
+}
+
+// TextFieldEquals matches text fields equal to value.
+func TextFieldEquals(field GID, value string) CustomFieldPredicate {
+	return CustomFieldPredicate{Field: field, Op: "value", Value: value}
+}
+
+// TextFieldContains matches text fields containing s.
+func TextFieldContains(field GID, s string) CustomFieldPredicate {
+	return CustomFieldPredicate{Field: field, Op: "contains", Value: s}
+}
+
+// TextFieldStartsWith matches text fields starting with prefix.
+func TextFieldStartsWith(field GID, prefix string) CustomFieldPredicate {
+	return CustomFieldPredicate{Field: field, Op: "starts_with", Value: prefix}
+}
+
+// TextFieldEndsWith matches text fields ending with suffix.
+func TextFieldEndsWith(field GID, suffix string) CustomFieldPredicate {
+	return CustomFieldPredicate{Field: field, Op: "ends_with", Value: suffix}
+}
+
+// NumberFieldEquals matches number fields equal to value.
+func NumberFieldEquals(field GID, value float64) CustomFieldPredicate {
+	return CustomFieldPredicate{Field: field, Op: "value", Value: formatNumber(value)}
+}
+
+// NumberFieldLessThan matches number fields less than value.
+func NumberFieldLessThan(field GID, value float64) CustomFieldPredicate {
+	return CustomFieldPredicate{Field: field, Op: "less_than", Value: formatNumber(value)}
+}
+
+// NumberFieldGreaterThan matches number fields greater than value.
+func NumberFieldGreaterThan(field GID, value float64) CustomFieldPredicate {
+	return CustomFieldPredicate{Field: field, Op: "greater_than", Value: formatNumber(value)}
+}
+
+// EnumFieldEquals matches enum fields set to the option.
+func EnumFieldEquals(field, option GID) CustomFieldPredicate {
+	return CustomFieldPredicate{Field: field, Op: "value", Value: string(option)}
+}
+
+// DateFieldEquals matches date fields set to date, a "YYYY-MM-DD" string.
+func DateFieldEquals(field GID, date string) CustomFieldPredicate {
+	return CustomFieldPredicate{Field: field, Op: "value", Value: date}
+}
+
+// DateFieldBefore matches date fields before date.
+func DateFieldBefore(field GID, date string) CustomFieldPredicate {
+	return CustomFieldPredicate{Field: field, Op: "before", Value: date}
+}
+
+// DateFieldAfter matches date fields after date.
+func DateFieldAfter(field GID, date string) CustomFieldPredicate {
+	return CustomFieldPredicate{Field: field, Op: "after", Value: date}
+}
+
+// MultiEnumFieldAny matches multi-enum fields set to any of the options.
+func MultiEnumFieldAny(field GID, options ...GID) CustomFieldPredicate {
+	return CustomFieldPredicate{Field: field, Op: "any", Value: joinGIDs(options)}
+}
+
+// MultiEnumFieldAll matches multi-enum fields set to all of the options.
+func MultiEnumFieldAll(field GID, options ...GID) CustomFieldPredicate {
+	return CustomFieldPredicate{Field: field, Op: "all", Value: joinGIDs(options)}
+}
+
+// PeopleFieldAny matches people fields including any of the users.
+func PeopleFieldAny(field GID, users ...GID) CustomFieldPredicate {
+	return CustomFieldPredicate{Field: field, Op: "any", Value: joinGIDs(users)}
+}
+
+// PeopleFieldAll matches people fields including all of the users.
+func PeopleFieldAll(field GID, users ...GID) CustomFieldPredicate {
+	return CustomFieldPredicate{Field: field, Op: "all", Value: joinGIDs(users)}
+}
+
+func formatNumber(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// values returns the query parameters of the search.
+func (q *TaskSearch) values() (url.Values, error) {
+	v, err := query.Values(q)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range q.CustomFields {
+		v.Set(fmt.Sprintf("custom_fields.%s.%s", p.Field, p.Op), p.Value)
+	}
+	return v, nil
+}
+
+// SearchTasks searches tasks in a workspace, newest first.
+//
+// The search endpoint has no offset paging: results are sorted by creation
+// time and the next page is queried with created_at.before set to the oldest
+// task seen. Tasks created in the same instant may be returned on two pages;
+// they are only included once. When a whole page was created in the same
+// millisecond, as after a bulk import, the tasks beyond it cannot be
+// reached: the tasks found so far are returned with an error wrapping
+// ErrSearchTruncated. Narrow the query to get them.
+//
+// https://developers.asana.com/reference/searchtasksforworkspace
+func (c *Client) SearchTasks(ctx context.Context, workspaceGID GID, q *TaskSearch) ([]Task, error) {
+	if q == nil {
+		q = &TaskSearch{}
+	}
+	params, err := q.values()
+	if err != nil {
+		return nil, err
+	}
+	params.Set("sort_by", "created_at")
+	params.Set("sort_ascending", "false")
+
+	optFields := q.OptFields
+	if len(optFields) == 0 {
+		optFields = defaultOptFields["tasks"]
+	}
+	opt := &Filter{OptFields: append(append([]string(nil), optFields...), "created_at"), Limit: maxSearchLimit}
+	if q.Limit > 0 && q.Limit < maxSearchLimit {
+		opt.Limit = uint32(q.Limit)
+	}
+
+	tasks := []Task{}
+	seen := map[GID]bool{}
+	for {
+		var page []Task
+		path := fmt.Sprintf("workspaces/%s/tasks/search?%s", workspaceGID, params.Encode())
+		if _, err := c.request(ctx, "GET", path, nil, nil, opt, &page); err != nil {
+			return nil, err
+		}
+		added := 0
+		var oldest time.Time
+		for _, t := range page {
+			if oldest.IsZero() || t.CreatedAt.Before(oldest) {
+				oldest = t.CreatedAt
+			}
+			if seen[t.GID] {
+				continue
+			}
+			seen[t.GID] = true
+			tasks = append(tasks, t)
+			added++
+			if q.Limit > 0 && len(tasks) == q.Limit {
+				return tasks, nil
+			}
+		}
+		if len(page) < int(opt.Limit) {
+			return tasks, nil
+		}
+		if added == 0 || page[0].CreatedAt.Equal(oldest) {
+			return tasks, fmt.Errorf("asana: more than %d tasks created at %s: %w", opt.Limit, oldest.UTC().Format(time.RFC3339Nano), ErrSearchTruncated)
+		}
+		// Overlap the next page by a millisecond, the precision of created_at,
+		// so that tasks created in the same instant as the oldest one but cut
+		// off by the limit are not skipped.
+		params.Set("created_at.before", oldest.Add(time.Millisecond).UTC().Format(time.RFC3339Nano))
+	}
+}
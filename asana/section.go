@@ -21,9 +21,9 @@ func (c *Client) GetSectionByExternalID(ctx context.Context, externalID string,
 // GetSection gets a section.
 //
 // https://asana.com/developers/api-reference/sections#get-single
-func (c *Client) GetSection(ctx context.Context, id int64, opt *Filter) (Section, error) {
+func (c *Client) GetSection(ctx context.Context, gid GID, opt *Filter) (Section, error) {
 	section := new(Section)
-	err := c.Request(ctx, fmt.Sprintf("sections/%d", id), opt, section)
+	err := c.Request(ctx, fmt.Sprintf("sections/%s", gid), opt, section)
 	return *section, err
 }
 
@@ -38,8 +38,8 @@ func (c *Client) DeleteSectionByExternalID(ctx context.Context, externalID strin
 // DeleteSection deletes a section.
 //
 // https://asana.com/developers/api-reference/sections#delete
-func (c *Client) DeleteSection(ctx context.Context, id int64, opt *Filter) error {
-	_, err := c.request(ctx, "DELETE", fmt.Sprintf("sections/%d", id), nil, nil, opt, nil)
+func (c *Client) DeleteSection(ctx context.Context, gid GID, opt *Filter) error {
+	_, err := c.request(ctx, "DELETE", fmt.Sprintf("sections/%s", gid), nil, nil, opt, nil)
 	return err
 }
 
@@ -55,9 +55,9 @@ func (c *Client) UpdateSectionByExternalID(ctx context.Context, externalID strin
 // UpdateSection updates a section.
 //
 // https://asana.com/developers/api-reference/sections#update
-func (c *Client) UpdateSection(ctx context.Context, id int64, su SectionUpdate, opt *Filter) (Section, error) {
+func (c *Client) UpdateSection(ctx context.Context, gid GID, su SectionUpdate, opt *Filter) (Section, error) {
 	section := new(Section)
-	_, err := c.request(ctx, "PUT", fmt.Sprintf("sections/%d", id), su, nil, opt, section)
+	_, err := c.request(ctx, "PUT", fmt.Sprintf("sections/%s", gid), su, nil, opt, section)
 	return *section, err
 }
 
@@ -70,13 +70,16 @@ func (c *Client) CreateSection(ctx context.Context, fields map[string]interface{
 	return *section, err
 }
 
+// IterProjectSections returns an iterator over sections in the project.
+//
+// https://asana.com/developers/api-reference/sections#find-project
+func (c *Client) IterProjectSections(projectGID GID, opt *Filter) *SectionIterator {
+	return newIterator[Section](c, fmt.Sprintf("projects/%s/sections", projectGID), opt)
+}
+
 // ListProjectSections gets sections in the project.
 //
 // https://asana.com/developers/api-reference/sections#find-project
-func (c *Client) ListProjectSections(ctx context.Context, projectID int64, opt *Filter) ([]Section, error) {
-	rets := []Section{}
-	if err := c.pagenate(ctx, fmt.Sprintf("projects/%d/sections", projectID), opt, &rets); err != nil {
-		return nil, err
-	}
-	return rets, nil
+func (c *Client) ListProjectSections(ctx context.Context, projectGID GID, opt *Filter) ([]Section, error) {
+	return listAll(ctx, c.IterProjectSections(projectGID, opt))
 }
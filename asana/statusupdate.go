@@ -0,0 +1,49 @@
+package asana
+
+import (
+	"context"
+	"fmt"
+)
+
+// IterStatusUpdates returns an iterator over the status updates of the
+// project, portfolio or goal set as opt.Parent, newest first.
+//
+// https://asana.com/developers/api-reference/status_updates#query
+func (c *Client) IterStatusUpdates(opt *Filter) *StatusUpdateIterator {
+	return newIterator[StatusUpdate](c, "status_updates", opt)
+}
+
+// ListStatusUpdates gets the status updates of the project, portfolio or
+// goal set as opt.Parent, newest first.
+//
+// https://asana.com/developers/api-reference/status_updates#query
+func (c *Client) ListStatusUpdates(ctx context.Context, opt *Filter) ([]StatusUpdate, error) {
+	return listAll(ctx, c.IterStatusUpdates(opt))
+}
+
+// GetStatusUpdate gets a status update.
+//
+// https://asana.com/developers/api-reference/status_updates#get-single
+func (c *Client) GetStatusUpdate(ctx context.Context, gid GID, opt *Filter) (StatusUpdate, error) {
+	update := new(StatusUpdate)
+	err := c.Request(ctx, fmt.Sprintf("status_updates/%s", gid), opt, update)
+	return *update, err
+}
+
+// CreateStatusUpdate posts a status update. fields must include the parent,
+// status_type and text, and may include title.
+//
+// https://asana.com/developers/api-reference/status_updates#create
+func (c *Client) CreateStatusUpdate(ctx context.Context, fields map[string]interface{}, opts *Filter) (StatusUpdate, error) {
+	update := new(StatusUpdate)
+	_, err := c.request(ctx, "POST", "status_updates", fields, nil, opts, update)
+	return *update, err
+}
+
+// DeleteStatusUpdate deletes a status update.
+//
+// https://asana.com/developers/api-reference/status_updates#delete
+func (c *Client) DeleteStatusUpdate(ctx context.Context, gid GID) error {
+	_, err := c.request(ctx, "DELETE", fmt.Sprintf("status_updates/%s", gid), nil, nil, nil, nil)
+	return err
+}
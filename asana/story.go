@@ -0,0 +1,51 @@
+package asana
+
+import (
+	"context"
+	"fmt"
+)
+
+// GetStory gets a story.
+//
+// https://asana.com/developers/api-reference/stories#get-single
+func (c *Client) GetStory(ctx context.Context, gid GID, opt *Filter) (Story, error) {
+	story := new(Story)
+	err := c.Request(ctx, fmt.Sprintf("stories/%s", gid), opt, story)
+	return *story, err
+}
+
+// CreateStory adds a comment to a task. fields must include either the
+// text or the html_text of the comment, and may include is_pinned.
+//
+// https://asana.com/developers/api-reference/stories#post-comment
+func (c *Client) CreateStory(ctx context.Context, taskGID GID, fields map[string]interface{}, opts *Filter) (Story, error) {
+	story := new(Story)
+	_, err := c.request(ctx, "POST", fmt.Sprintf("tasks/%s/stories", taskGID), fields, nil, opts, story)
+	return *story, err
+}
+
+// UpdateStory updates a comment. Only comments made by the authenticated
+// user can be edited.
+//
+// https://asana.com/developers/api-reference/stories#update
+func (c *Client) UpdateStory(ctx context.Context, gid GID, su StoryUpdate, opt *Filter) (Story, error) {
+	story := new(Story)
+	_, err := c.request(ctx, "PUT", fmt.Sprintf("stories/%s", gid), su, nil, opt, story)
+	return *story, err
+}
+
+// PinStory pins a comment to the top of its task, or unpins it.
+//
+// https://asana.com/developers/api-reference/stories#update
+func (c *Client) PinStory(ctx context.Context, gid GID, pinned bool) (Story, error) {
+	return c.UpdateStory(ctx, gid, StoryUpdate{IsPinned: &pinned}, nil)
+}
+
+// DeleteStory deletes a comment. Only comments made by the authenticated
+// user can be deleted.
+//
+// https://asana.com/developers/api-reference/stories#delete
+func (c *Client) DeleteStory(ctx context.Context, gid GID) error {
+	_, err := c.request(ctx, "DELETE", fmt.Sprintf("stories/%s", gid), nil, nil, nil, nil)
+	return err
+}
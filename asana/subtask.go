@@ -0,0 +1,154 @@
+package asana
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+const defaultTaskTreeConcurrency = 4
+
+// IterSubtasks returns an iterator over the subtasks of a task.
+//
+// https://asana.com/developers/api-reference/tasks#subtasks
+func (c *Client) IterSubtasks(taskGID GID, opt *Filter) *TaskIterator {
+	return newIterator[Task](c, fmt.Sprintf("tasks/%s/subtasks", taskGID), opt)
+}
+
+// ListSubtasks gets the subtasks of a task.
+//
+// https://asana.com/developers/api-reference/tasks#subtasks
+func (c *Client) ListSubtasks(ctx context.Context, taskGID GID, opt *Filter) ([]Task, error) {
+	return listAll(ctx, c.IterSubtasks(taskGID, opt))
+}
+
+// CreateSubtask creates a subtask of a task.
+//
+// https://asana.com/developers/api-reference/tasks#subtasks
+func (c *Client) CreateSubtask(ctx context.Context, parentGID GID, fields map[string]interface{}, opts *Filter) (Task, error) {
+	task := new(Task)
+	_, err := c.request(ctx, "POST", fmt.Sprintf("tasks/%s/subtasks", parentGID), fields, nil, opts, task)
+	return *task, err
+}
+
+// SetParent changes the parent of a task.
+//
+// https://asana.com/developers/api-reference/tasks#subtasks
+func (c *Client) SetParent(ctx context.Context, taskGID GID, pu ParentUpdate, opts *Filter) (Task, error) {
+	task := new(Task)
+	_, err := c.request(ctx, "POST", fmt.Sprintf("tasks/%s/setParent", taskGID), pu, nil, opts, task)
+	return *task, err
+}
+
+type (
+	// TaskTree is a task and its subtasks, recursively.
+	TaskTree struct {
+		Task     Task
+		Subtasks []*TaskTree
+	}
+
+	// TaskTreeOptions configures GetTaskTree.
+	TaskTreeOptions struct {
+		// Filter is the filter every task is fetched with.
+		Filter *Filter
+		// Concurrency is the maximum number of requests in flight. Defaults to 4.
+		Concurrency int
+		// MaxDepth limits the levels of subtasks fetched, 0 for all.
+		MaxDepth int
+	}
+)
+
+// GetTaskTree gets a task and its subtasks, recursively. Subtasks are
+// fetched concurrently, in the order Asana lists them.
+func (c *Client) GetTaskTree(ctx context.Context, taskGID GID, opt *TaskTreeOptions) (*TaskTree, error) {
+	if opt == nil {
+		opt = &TaskTreeOptions{}
+	}
+	filter := opt.Filter
+	if filter == nil {
+		filter = &Filter{OptFields: defaultOptFields["tasks"]}
+	}
+	concurrency := opt.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultTaskTreeConcurrency
+	}
+
+	task, err := c.GetTask(ctx, taskGID, filter)
+	if err != nil {
+		return nil, err
+	}
+	root := &TaskTree{Task: task}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		wg       sync.WaitGroup
+		sem      = make(chan struct{}, concurrency)
+		mu       sync.Mutex
+		firstErr error
+		seen     = map[GID]bool{taskGID: true}
+	)
+	var expand func(node *TaskTree, depth int)
+	expand = func(node *TaskTree, depth int) {
+		defer wg.Done()
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+		subtasks, err := c.ListSubtasks(ctx, node.Task.GID, filter)
+		<-sem
+
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("asana: listing subtasks of task %s: %w", node.Task.GID, err)
+				cancel()
+			}
+			return
+		}
+		for _, t := range subtasks {
+			// Guard against a malformed hierarchy looping back on itself.
+			if seen[t.GID] {
+				continue
+			}
+			seen[t.GID] = true
+			child := &TaskTree{Task: t}
+			node.Subtasks = append(node.Subtasks, child)
+			if opt.MaxDepth == 0 || depth < opt.MaxDepth {
+				wg.Add(1)
+				go expand(child, depth+1)
+			}
+		}
+	}
+	wg.Add(1)
+	go expand(root, 1)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+// Walk calls fn for the task and every subtask, depth first, with its
+// depth below the root. It stops at the first error, which is returned.
+func (t *TaskTree) Walk(fn func(task *Task, depth int) error) error {
+	return t.walk(fn, 0)
+}
+
+func (t *TaskTree) walk(fn func(task *Task, depth int) error, depth int) error {
+	if err := fn(&t.Task, depth); err != nil {
+		return err
+	}
+	for _, sub := range t.Subtasks {
+		if err := sub.walk(fn, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package asana
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// SyncTokenStore persists event sync tokens, keyed by resource.
+type SyncTokenStore interface {
+	// LoadSyncToken returns the token saved for a resource, or "" if there is none.
+	LoadSyncToken(ctx context.Context, resourceGID GID) (string, error)
+	// SaveSyncToken saves the token for a resource.
+	SaveSyncToken(ctx context.Context, resourceGID GID, token string) error
+}
+
+// MemorySyncTokenStore keeps sync tokens in memory.
+// It is safe for concurrent use.
+type MemorySyncTokenStore struct {
+	mu     sync.Mutex
+	tokens map[GID]string
+}
+
+// NewMemorySyncTokenStore returns an empty in-memory store.
+func NewMemorySyncTokenStore() *MemorySyncTokenStore {
+	return &MemorySyncTokenStore{tokens: map[GID]string{}}
+}
+
+func (s *MemorySyncTokenStore) LoadSyncToken(ctx context.Context, resourceGID GID) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tokens[resourceGID], nil
+}
+
+func (s *MemorySyncTokenStore) SaveSyncToken(ctx context.Context, resourceGID GID, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens[resourceGID] = token
+	return nil
+}
+
+// FileSyncTokenStore keeps sync tokens in a JSON file. The file is replaced
+// atomically on every save, so it is never left half-written.
+// It is safe for concurrent use within one process.
+type FileSyncTokenStore struct {
+	path string
+
+	mu     sync.Mutex
+	tokens map[GID]string // nil until the file is read
+}
+
+// NewFileSyncTokenStore returns a store backed by the file at path.
+// The file is created on the first save.
+func NewFileSyncTokenStore(path string) *FileSyncTokenStore {
+	return &FileSyncTokenStore{path: path}
+}
+
+func (s *FileSyncTokenStore) LoadSyncToken(ctx context.Context, resourceGID GID) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.read(); err != nil {
+		return "", err
+	}
+	return s.tokens[resourceGID], nil
+}
+
+func (s *FileSyncTokenStore) SaveSyncToken(ctx context.Context, resourceGID GID, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.read(); err != nil {
+		return err
+	}
+	tokens := make(map[GID]string, len(s.tokens)+1)
+	for k, v := range s.tokens {
+		tokens[k] = v
+	}
+	tokens[resourceGID] = token
+	if err := s.write(tokens); err != nil {
+		return err
+	}
+	s.tokens = tokens
+	return nil
+}
+
+func (s *FileSyncTokenStore) read() error {
+	if s.tokens != nil {
+		return nil
+	}
+	b, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		s.tokens = map[GID]string{}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	tokens := map[GID]string{}
+	if err := json.Unmarshal(b, &tokens); err != nil {
+		return err
+	}
+	s.tokens = tokens
+	return nil
+}
+
+func (s *FileSyncTokenStore) write(tokens map[GID]string) error {
+	b, err := json.MarshalIndent(tokens, "", "  ")
+	if err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), s.path)
+}
@@ -5,15 +5,18 @@ import (
 	"fmt"
 )
 
+// IterTasks returns an iterator over tasks.
+//
+// https://asana.com/developers/api-reference/tasks#query
+func (c *Client) IterTasks(opt *Filter) *TaskIterator {
+	return newIterator[Task](c, "tasks", opt)
+}
+
 // ListTasks gets tasks.
 //
 // https://asana.com/developers/api-reference/tasks#query
 func (c *Client) ListTasks(ctx context.Context, opt *Filter) ([]Task, error) {
-	rets := []Task{}
-	if err := c.pagenate(ctx, "tasks", opt, &rets); err != nil {
-		return nil, err
-	}
-	return rets, nil
+	return listAll(ctx, c.IterTasks(opt))
 }
 
 func externalTaskQuery(externalID string) string {
@@ -32,9 +35,9 @@ func (c *Client) GetTaskByExternalID(ctx context.Context, externalID string, opt
 // GetTask gets a task.
 //
 // https://asana.com/developers/api-reference/tasks#get
-func (c *Client) GetTask(ctx context.Context, id int64, opt *Filter) (Task, error) {
+func (c *Client) GetTask(ctx context.Context, gid GID, opt *Filter) (Task, error) {
 	task := new(Task)
-	err := c.Request(ctx, fmt.Sprintf("tasks/%d", id), opt, task)
+	err := c.Request(ctx, fmt.Sprintf("tasks/%s", gid), opt, task)
 	return *task, err
 }
 
@@ -49,8 +52,8 @@ func (c *Client) DeleteTaskByExternalID(ctx context.Context, externalID string,
 // DeleteTask deletes a task.
 //
 // https://asana.com/developers/api-reference/tasks#delete
-func (c *Client) DeleteTask(ctx context.Context, id int64, opt *Filter) error {
-	_, err := c.request(ctx, "DELETE", fmt.Sprintf("tasks/%d", id), nil, nil, opt, nil)
+func (c *Client) DeleteTask(ctx context.Context, gid GID, opt *Filter) error {
+	_, err := c.request(ctx, "DELETE", fmt.Sprintf("tasks/%s", gid), nil, nil, opt, nil)
 	return err
 }
 
@@ -66,9 +69,9 @@ func (c *Client) UpdateTaskByExternalID(ctx context.Context, externalID string,
 // UpdateTask updates a task.
 //
 // https://asana.com/developers/api-reference/tasks#update
-func (c *Client) UpdateTask(ctx context.Context, id int64, tu TaskUpdate, opt *Filter) (Task, error) {
+func (c *Client) UpdateTask(ctx context.Context, gid GID, tu TaskUpdate, opt *Filter) (Task, error) {
 	task := new(Task)
-	_, err := c.request(ctx, "PUT", fmt.Sprintf("tasks/%d", id), tu, nil, opt, task)
+	_, err := c.request(ctx, "PUT", fmt.Sprintf("tasks/%s", gid), tu, nil, opt, task)
 	return *task, err
 }
 
@@ -81,46 +84,49 @@ func (c *Client) CreateTask(ctx context.Context, fields map[string]interface{},
 	return *task, err
 }
 
+// IterProjectTasks returns an iterator over tasks in the project.
+//
+// https://asana.com/developers/api-reference/tasks#query
+func (c *Client) IterProjectTasks(projectGID GID, opt *Filter) *TaskIterator {
+	return newIterator[Task](c, fmt.Sprintf("projects/%s/tasks", projectGID), opt)
+}
+
 // ListProjectTasks gets tasks in the project.
 //
 // https://asana.com/developers/api-reference/tasks#query
-func (c *Client) ListProjectTasks(ctx context.Context, projectID int64, opt *Filter) ([]Task, error) {
-	rets := []Task{}
-	if err := c.pagenate(ctx, fmt.Sprintf("projects/%d/tasks", projectID), opt, &rets); err != nil {
-		return nil, err
-	}
-	return rets, nil
+func (c *Client) ListProjectTasks(ctx context.Context, projectGID GID, opt *Filter) ([]Task, error) {
+	return listAll(ctx, c.IterProjectTasks(projectGID, opt))
 }
 
 // AddTagByExternalID adds a tag to a task.
 //
 // https://asana.com/developers/api-reference/tasks#tags
-func (c *Client) AddTagByExternalID(ctx context.Context, externalID string, tagID int64, opts *Filter) error {
-	_, err := c.request(ctx, "POST", fmt.Sprintf("tasks/external:%s/addTag", externalID), map[string]interface{}{"tag": tagID}, nil, opts, nil)
+func (c *Client) AddTagByExternalID(ctx context.Context, externalID string, tagGID GID, opts *Filter) error {
+	_, err := c.request(ctx, "POST", fmt.Sprintf("tasks/external:%s/addTag", externalID), map[string]interface{}{"tag": tagGID}, nil, opts, nil)
 	return err
 }
 
 // RemoveTagByExternalID removes a tag to a task.
 //
 // https://asana.com/developers/api-reference/tasks#tags
-func (c *Client) RemoveTagByExternalID(ctx context.Context, externalID string, tagID int64, opts *Filter) error {
-	_, err := c.request(ctx, "POST", fmt.Sprintf("tasks/external:%s/removeTag", externalID), map[string]interface{}{"tag": tagID}, nil, opts, nil)
+func (c *Client) RemoveTagByExternalID(ctx context.Context, externalID string, tagGID GID, opts *Filter) error {
+	_, err := c.request(ctx, "POST", fmt.Sprintf("tasks/external:%s/removeTag", externalID), map[string]interface{}{"tag": tagGID}, nil, opts, nil)
 	return err
 }
 
 // AddTag adds a tag to a task.
 //
 // https://asana.com/developers/api-reference/tasks#tags
-func (c *Client) AddTag(ctx context.Context, taskID int64, tagID int64, opts *Filter) error {
-	_, err := c.request(ctx, "POST", fmt.Sprintf("tasks/%d/addTag", taskID), map[string]interface{}{"tag": tagID}, nil, opts, nil)
+func (c *Client) AddTag(ctx context.Context, taskGID GID, tagGID GID, opts *Filter) error {
+	_, err := c.request(ctx, "POST", fmt.Sprintf("tasks/%s/addTag", taskGID), map[string]interface{}{"tag": tagGID}, nil, opts, nil)
 	return err
 }
 
 // RemoveTag removes a tag to a task.
 //
 // https://asana.com/developers/api-reference/tasks#tags
-func (c *Client) RemoveTag(ctx context.Context, taskID int64, tagID int64, opts *Filter) error {
-	_, err := c.request(ctx, "POST", fmt.Sprintf("tasks/%d/removeTag", taskID), map[string]interface{}{"tag": tagID}, nil, opts, nil)
+func (c *Client) RemoveTag(ctx context.Context, taskGID GID, tagGID GID, opts *Filter) error {
+	_, err := c.request(ctx, "POST", fmt.Sprintf("tasks/%s/removeTag", taskGID), map[string]interface{}{"tag": tagGID}, nil, opts, nil)
 	return err
 }
 
@@ -143,31 +149,26 @@ func (c *Client) RemoveProjectByExternalID(ctx context.Context, externalID strin
 // AddProject adds a project to a task.
 //
 // https://asana.com/developers/api-reference/tasks#projects
-func (c *Client) AddProject(ctx context.Context, taskID int64, mu MembershipUpdate, opts *Filter) error {
-	_, err := c.request(ctx, "POST", fmt.Sprintf("tasks/%d/addProject", taskID), mu, nil, opts, nil)
+func (c *Client) AddProject(ctx context.Context, taskGID GID, mu MembershipUpdate, opts *Filter) error {
+	_, err := c.request(ctx, "POST", fmt.Sprintf("tasks/%s/addProject", taskGID), mu, nil, opts, nil)
 	return err
 }
 
 // RemoveProject removes a project to a task.
 //
 // https://asana.com/developers/api-reference/tasks#projects
-func (c *Client) RemoveProject(ctx context.Context, taskID int64, mu MembershipUpdate, opts *Filter) error {
-	_, err := c.request(ctx, "POST", fmt.Sprintf("tasks/%d/removeProject", taskID), mu, nil, opts, nil)
+func (c *Client) RemoveProject(ctx context.Context, taskGID GID, mu MembershipUpdate, opts *Filter) error {
+	_, err := c.request(ctx, "POST", fmt.Sprintf("tasks/%s/removeProject", taskGID), mu, nil, opts, nil)
 	return err
 }
 
-// GetCustomFieldValue Get a custom_field value from a task
+// GetCustomFieldValue Get a custom_field value from a task, formatted as
+// text. Numbers are formatted with the precision of the field and lists of
+// enum options or people are joined with ", ". Unset fields yield "".
 func (t *Task) GetCustomFieldValue(name string) (string, error) {
 	for _, cf := range t.CustomFields {
 		if cf.Name == name {
-			switch cf.Type {
-			case "enum":
-				return cf.EnumValue.Name, nil
-			case "text":
-				return cf.TextValue, nil
-			case "number":
-				return string(cf.NumberValue), nil
-			}
+			return cf.formatValue(), nil
 		}
 	}
 	return "", fmt.Errorf("Custom field '%s' not found", name)
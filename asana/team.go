@@ -0,0 +1,109 @@
+package asana
+
+import (
+	"context"
+	"fmt"
+)
+
+// IterTeams returns an iterator over the teams of an organization.
+//
+// https://asana.com/developers/api-reference/teams#find-organization
+func (c *Client) IterTeams(organizationGID GID, opt *Filter) *TeamIterator {
+	return newIterator[Team](c, fmt.Sprintf("organizations/%s/teams", organizationGID), opt)
+}
+
+// ListTeams gets the teams of an organization.
+//
+// https://asana.com/developers/api-reference/teams#find-organization
+func (c *Client) ListTeams(ctx context.Context, organizationGID GID, opt *Filter) ([]Team, error) {
+	return listAll(ctx, c.IterTeams(organizationGID, opt))
+}
+
+// IterUserTeams returns an iterator over the teams of a user in the
+// organization set as opt.Workspace.
+//
+// https://asana.com/developers/api-reference/teams#find-user
+func (c *Client) IterUserTeams(userGID GID, opt *Filter) *TeamIterator {
+	return newIterator[Team](c, fmt.Sprintf("users/%s/teams", userGID), opt)
+}
+
+// ListUserTeams gets the teams of a user in the organization set as opt.Workspace.
+//
+// https://asana.com/developers/api-reference/teams#find-user
+func (c *Client) ListUserTeams(ctx context.Context, userGID GID, opt *Filter) ([]Team, error) {
+	return listAll(ctx, c.IterUserTeams(userGID, opt))
+}
+
+// GetTeam gets a team.
+//
+// https://asana.com/developers/api-reference/teams#get-single
+func (c *Client) GetTeam(ctx context.Context, gid GID, opt *Filter) (Team, error) {
+	team := new(Team)
+	err := c.Request(ctx, fmt.Sprintf("teams/%s", gid), opt, team)
+	return *team, err
+}
+
+// CreateTeam creates a team. fields must include the organization and name.
+//
+// https://asana.com/developers/api-reference/teams#create
+func (c *Client) CreateTeam(ctx context.Context, fields map[string]interface{}, opts *Filter) (Team, error) {
+	team := new(Team)
+	_, err := c.request(ctx, "POST", "teams", fields, nil, opts, team)
+	return *team, err
+}
+
+// UpdateTeam updates a team.
+//
+// https://asana.com/developers/api-reference/teams#update
+func (c *Client) UpdateTeam(ctx context.Context, gid GID, tu TeamUpdate, opt *Filter) (Team, error) {
+	team := new(Team)
+	_, err := c.request(ctx, "PUT", fmt.Sprintf("teams/%s", gid), tu, nil, opt, team)
+	return *team, err
+}
+
+// AddUserToTeam adds a user to a team. The user must be a member of the
+// organization of the team.
+//
+// https://asana.com/developers/api-reference/teams#add-user
+func (c *Client) AddUserToTeam(ctx context.Context, teamGID GID, userGID GID, opts *Filter) (TeamMembership, error) {
+	membership := new(TeamMembership)
+	_, err := c.request(ctx, "POST", fmt.Sprintf("teams/%s/addUser", teamGID), map[string]interface{}{"user": userGID}, nil, opts, membership)
+	return *membership, err
+}
+
+// RemoveUserFromTeam removes a user from a team.
+//
+// https://asana.com/developers/api-reference/teams#remove-user
+func (c *Client) RemoveUserFromTeam(ctx context.Context, teamGID GID, userGID GID, opts *Filter) error {
+	_, err := c.request(ctx, "POST", fmt.Sprintf("teams/%s/removeUser", teamGID), map[string]interface{}{"user": userGID}, nil, opts, nil)
+	return err
+}
+
+// IterTeamMemberships returns an iterator over the memberships of a team.
+//
+// https://asana.com/developers/api-reference/team_memberships#query
+func (c *Client) IterTeamMemberships(teamGID GID, opt *Filter) *TeamMembershipIterator {
+	return newIterator[TeamMembership](c, fmt.Sprintf("teams/%s/team_memberships", teamGID), opt)
+}
+
+// ListTeamMemberships gets the memberships of a team.
+//
+// https://asana.com/developers/api-reference/team_memberships#query
+func (c *Client) ListTeamMemberships(ctx context.Context, teamGID GID, opt *Filter) ([]TeamMembership, error) {
+	return listAll(ctx, c.IterTeamMemberships(teamGID, opt))
+}
+
+// IterTeamProjects returns an iterator over the projects of a team.
+// opt.Archived only returns archived projects.
+//
+// https://asana.com/developers/api-reference/projects#get-team
+func (c *Client) IterTeamProjects(teamGID GID, opt *Filter) *ProjectIterator {
+	return newIterator[Project](c, fmt.Sprintf("teams/%s/projects", teamGID), opt)
+}
+
+// ListTeamProjects gets the projects of a team.
+//
+// https://asana.com/developers/api-reference/projects#get-team
+func (c *Client) ListTeamProjects(ctx context.Context, teamGID GID, opt *Filter) ([]Project, error) {
+	return listAll(ctx, c.IterTeamProjects(teamGID, opt))
+}
@@ -2,60 +2,66 @@ package asana
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"net/url"
 )
 
+// IterWebhooks returns an iterator over webhooks.
+//
+// https://asana.com/developers/api-reference/webhooks#get
+func (c *Client) IterWebhooks(opt *Filter) *WebhookIterator {
+	return newIterator[Webhook](c, "webhooks", opt)
+}
+
 // ListWebhooks gets webhooks.
 //
 // https://asana.com/developers/api-reference/webhooks#get
 func (c *Client) ListWebhooks(ctx context.Context, opt *Filter) ([]Webhook, error) {
-	webhooks := []Webhook{}
-	for {
-		page := []Webhook{}
-		next, err := c.request(ctx, "GET", "webhooks", nil, nil, opt, &page)
-		if err != nil {
-			return nil, err
-		}
-		webhooks = append(webhooks, page...)
-		if next == nil {
-			break
-		} else {
-			newOpt := *opt
-			opt = &newOpt
-			opt.Offset = next.Offset
-		}
-	}
-	return webhooks, nil
+	return listAll(ctx, c.IterWebhooks(opt))
 }
 
 // GetWebhook gets a webhook.
 //
 // https://asana.com/developers/api-reference/webhooks#get-single
-func (c *Client) GetWebhook(ctx context.Context, id int64) (Webhook, error) {
+func (c *Client) GetWebhook(ctx context.Context, gid GID) (Webhook, error) {
 	webhook := new(Webhook)
-	err := c.Request(ctx, fmt.Sprintf("webhooks/%d", id), nil, &webhook)
+	err := c.Request(ctx, fmt.Sprintf("webhooks/%s", gid), nil, &webhook)
 	return *webhook, err
 }
 
-// CreateWebhook creates a webhook.
+// CreateWebhook creates a webhook on a resource, delivering to target the
+// events matching filters, or all events when there are none.
 //
 // https://asana.com/developers/api-reference/webhooks#create
-func (c *Client) CreateWebhook(ctx context.Context, id int64, target string) (Webhook, error) {
+func (c *Client) CreateWebhook(ctx context.Context, gid GID, target string, filters ...WebhookFilter) (Webhook, error) {
 	webhook := new(Webhook)
-	p := url.Values{
-		"resource": []string{fmt.Sprintf("%d", id)},
-		"target":   []string{target},
+	data := map[string]interface{}{
+		"resource": gid,
+		"target":   target,
+	}
+	if len(filters) > 0 {
+		data["filters"] = filters
 	}
-	_, err := c.request(ctx, "POST", "webhooks", nil, p, nil, &webhook)
+	_, err := c.request(ctx, "POST", "webhooks", data, nil, nil, webhook)
 	return *webhook, err
 }
 
+// CreateWorkspaceWebhook creates a webhook on a whole workspace.
+// Asana requires filters on workspace webhooks.
+//
+// https://asana.com/developers/api-reference/webhooks#create
+func (c *Client) CreateWorkspaceWebhook(ctx context.Context, workspaceGID GID, target string, filters ...WebhookFilter) (Webhook, error) {
+	if len(filters) == 0 {
+		return Webhook{}, errors.New("asana: workspace webhooks need at least one filter")
+	}
+	return c.CreateWebhook(ctx, workspaceGID, target, filters...)
+}
+
 // DeleteWebhook deletes a webhook.
 //
 // https://asana.com/developers/api-reference/webhooks#delete
-func (c *Client) DeleteWebhook(ctx context.Context, id int64) error {
+func (c *Client) DeleteWebhook(ctx context.Context, gid GID) error {
 	var resp interface{} // Empty response
-	_, err := c.request(ctx, "DELETE", fmt.Sprintf("webhooks/%d", id), nil, nil, nil, &resp)
+	_, err := c.request(ctx, "DELETE", fmt.Sprintf("webhooks/%s", gid), nil, nil, nil, &resp)
 	return err
 }
@@ -0,0 +1,174 @@
+package asana
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+)
+
+const defaultMaxWebhookBody = 10 << 20
+
+// WebhookSecretStore persists the secrets webhooks are signed with, keyed
+// by the webhook they belong to, see WebhookHandler.Key.
+type WebhookSecretStore interface {
+	// LoadWebhookSecret returns the secret saved for key, or "" if there is none.
+	LoadWebhookSecret(ctx context.Context, key string) (string, error)
+	// SaveWebhookSecret saves the secret for key. Saving "" forgets it.
+	SaveWebhookSecret(ctx context.Context, key, secret string) error
+}
+
+// MemoryWebhookSecretStore keeps webhook secrets in memory.
+// It is safe for concurrent use.
+type MemoryWebhookSecretStore struct {
+	mu      sync.Mutex
+	secrets map[string]string
+}
+
+// NewMemoryWebhookSecretStore returns an empty in-memory store.
+func NewMemoryWebhookSecretStore() *MemoryWebhookSecretStore {
+	return &MemoryWebhookSecretStore{secrets: map[string]string{}}
+}
+
+func (s *MemoryWebhookSecretStore) LoadWebhookSecret(ctx context.Context, key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.secrets[key], nil
+}
+
+func (s *MemoryWebhookSecretStore) SaveWebhookSecret(ctx context.Context, key, secret string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if secret == "" {
+		delete(s.secrets, key)
+		return nil
+	}
+	s.secrets[key] = secret
+	return nil
+}
+
+// WebhookHandler is an http.Handler receiving Asana webhooks.
+//
+// It answers the X-Hook-Secret handshake Asana performs when a webhook is
+// created, saving the secret in Secrets. Later deliveries must carry a valid
+// X-Hook-Signature, the HMAC-SHA256 of the body with that secret; their
+// events are passed to OnEvents.
+//
+// A handshake is refused while a secret is stored for its key: anyone can
+// send one, and replacing the secret would let them sign forged deliveries.
+// To recreate a webhook on the same target, forget its secret first.
+type WebhookHandler struct {
+	// Secrets stores the handshake secret of each webhook.
+	Secrets WebhookSecretStore
+	// OnEvents is called with the events of every verified delivery.
+	// Returning an error responds with 500 so that Asana retries it.
+	OnEvents func(ctx context.Context, events []Event) error
+	// Key identifies the webhook a request belongs to.
+	// Defaults to the request path, so every webhook needs its own target path.
+	Key func(r *http.Request) string
+	// AcceptHandshake decides whether a handshake request is answered,
+	// replacing any stored secret. Defaults to accepting a handshake only
+	// when no secret is stored for its key.
+	AcceptHandshake func(r *http.Request) bool
+	// MaxBodySize limits the size of a delivery. Defaults to 10MB.
+	MaxBodySize int64
+}
+
+// NewWebhookHandler returns a handler saving secrets in secrets and passing
+// verified events to onEvents.
+func NewWebhookHandler(secrets WebhookSecretStore, onEvents func(ctx context.Context, events []Event) error) *WebhookHandler {
+	return &WebhookHandler{Secrets: secrets, OnEvents: onEvents}
+}
+
+// VerifyWebhookSignature reports whether signature, as sent in the
+// X-Hook-Signature header, is the signature of body with secret.
+func VerifyWebhookSignature(secret string, body []byte, signature string) bool {
+	got, err := hex.DecodeString(signature)
+	if err != nil || secret == "" {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	ctx := r.Context()
+	key := r.URL.Path
+	if h.Key != nil {
+		key = h.Key(r)
+	}
+
+	if secret := r.Header.Get("X-Hook-Secret"); secret != "" {
+		accept := true
+		if h.AcceptHandshake != nil {
+			accept = h.AcceptHandshake(r)
+		} else {
+			stored, err := h.Secrets.LoadWebhookSecret(ctx, key)
+			if err != nil {
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+			accept = stored == ""
+		}
+		if !accept {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+		if err := h.Secrets.SaveWebhookSecret(ctx, key, secret); err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("X-Hook-Secret", secret)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	max := h.MaxBodySize
+	if max <= 0 {
+		max = defaultMaxWebhookBody
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, max+1))
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	if int64(len(body)) > max {
+		http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+		return
+	}
+	secret, err := h.Secrets.LoadWebhookSecret(ctx, key)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if !VerifyWebhookSignature(secret, body, r.Header.Get("X-Hook-Signature")) {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	var payload struct {
+		Events []Event `json:"events"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	// Deliveries without events are heartbeats.
+	if len(payload.Events) > 0 && h.OnEvents != nil {
+		if err := h.OnEvents(ctx, payload.Events); err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}